@@ -2,10 +2,96 @@ package cobra
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
 type PositionalArgs func(cmd *Command, args []string) error
 
+// ArgCountAnnotation is the annotation of a command with a custom Args
+// function that tells generators, like those of completion specs, how many
+// positional arguments it accepts, as they can't tell from the function:
+// "2" for exactly two, "1-3" for one to three and "1-" for at least one.
+const ArgCountAnnotation = "cobra_annotation_arg_count"
+
+// ArgCounts returns the smallest and largest number of positional arguments
+// c accepts, with -1 as the largest if there is no limit. They follow from
+// Args if it is nil or one of the validators of this package, and are read
+// from ArgCountAnnotation otherwise. ok is false if they are unknown.
+func (c *Command) ArgCounts() (min, max int, ok bool) {
+	switch {
+	case c.Args == nil:
+		// Legacy validation: root commands with subcommands take none.
+		if !c.HasParent() && c.HasSubCommands() {
+			return 0, 0, true
+		}
+		return 0, -1, true
+	case sameArgs(c.Args, NoArgs):
+		return 0, 0, true
+	case sameArgs(c.Args, ArbitraryArgs):
+		return 0, -1, true
+	case sameArgs(c.Args, countedArgs(0, 0, nil)):
+		counts := c.Args(argCountsQuery, nil).(argCounts)
+		return counts.min, counts.max, true
+	}
+	if count, found := c.Annotations[ArgCountAnnotation]; found {
+		return parseArgCount(count)
+	}
+	return 0, -1, false
+}
+
+// argCountsQuery is the command the validators returned by countedArgs are
+// called with by ArgCounts to learn their bounds.
+var argCountsQuery = &Command{}
+
+// argCounts is the error the validators returned by countedArgs return when
+// they are called with argCountsQuery. max is -1 if there is no limit.
+type argCounts struct {
+	min, max int
+}
+
+func (counts argCounts) Error() string {
+	return fmt.Sprintf("accepts between %d and %d arg(s)", counts.min, counts.max)
+}
+
+// countedArgs returns validate as a validator that accepts between min and
+// max args, which ArgCounts can tell.
+func countedArgs(min, max int, validate PositionalArgs) PositionalArgs {
+	return func(cmd *Command, args []string) error {
+		if cmd == argCountsQuery {
+			return argCounts{min, max}
+		}
+		return validate(cmd, args)
+	}
+}
+
+// parseArgCount parses the value of ArgCountAnnotation.
+func parseArgCount(count string) (min, max int, ok bool) {
+	parts := strings.SplitN(count, "-", 2)
+	min, err := strconv.Atoi(parts[0])
+	if err != nil || min < 0 {
+		return 0, -1, false
+	}
+	if len(parts) == 1 {
+		return min, min, true
+	}
+	if len(parts[1]) == 0 {
+		return min, -1, true
+	}
+	max, err = strconv.Atoi(parts[1])
+	if err != nil || max < min {
+		return 0, -1, false
+	}
+	return min, max, true
+}
+
+// sameArgs reports whether a and b are the same function. All closures made
+// by the same function literal are the same function this way.
+func sameArgs(a, b PositionalArgs) bool {
+	return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+}
+
 // Legacy arg validation has the following behaviour:
 // - root commands with no subcommands can take arbitrary arguments
 // - root commands with subcommands will do subcommand validity checking
@@ -32,10 +118,15 @@ func NoArgs(cmd *Command, args []string) error {
 }
 
 // OnlyValidArgs returns an error if any args are not in the list of ValidArgs.
+// Only the values of ValidArgs are compared, not their descriptions.
 func OnlyValidArgs(cmd *Command, args []string) error {
 	if len(cmd.ValidArgs) > 0 {
+		validArgs := make([]string, 0, len(cmd.ValidArgs))
+		for _, v := range cmd.ValidArgs {
+			validArgs = append(validArgs, validArgValue(v))
+		}
 		for _, v := range args {
-			if !stringInSlice(v, cmd.ValidArgs) {
+			if !stringInSlice(v, validArgs) {
 				return fmt.Errorf("invalid argument %q for %q%s", v, cmd.CommandPath(), cmd.findSuggestions(args[0]))
 			}
 		}
@@ -43,6 +134,24 @@ func OnlyValidArgs(cmd *Command, args []string) error {
 	return nil
 }
 
+// validArgValue returns the value of an entry of ValidArgs, which may be
+// followed by a tab and a description of the value.
+func validArgValue(arg string) string {
+	if i := strings.Index(arg, "\t"); i >= 0 {
+		return arg[:i]
+	}
+	return arg
+}
+
+// validArgDescription returns the description of an entry of ValidArgs,
+// or an empty string if it has none.
+func validArgDescription(arg string) string {
+	if i := strings.Index(arg, "\t"); i >= 0 {
+		return arg[i+1:]
+	}
+	return ""
+}
+
 // ArbitraryArgs never returns an error.
 func ArbitraryArgs(cmd *Command, args []string) error {
 	return nil
@@ -50,52 +159,52 @@ func ArbitraryArgs(cmd *Command, args []string) error {
 
 // MinimumNArgs returns an error if there is not at least N args.
 func MinimumNArgs(n int) PositionalArgs {
-	return func(cmd *Command, args []string) error {
+	return countedArgs(n, -1, func(cmd *Command, args []string) error {
 		if len(args) < n {
 			return fmt.Errorf("requires at least %d arg(s), only received %d", n, len(args))
 		}
 		return nil
-	}
+	})
 }
 
 // MaximumNArgs returns an error if there are more than N args.
 func MaximumNArgs(n int) PositionalArgs {
-	return func(cmd *Command, args []string) error {
+	return countedArgs(0, n, func(cmd *Command, args []string) error {
 		if len(args) > n {
 			return fmt.Errorf("accepts at most %d arg(s), received %d", n, len(args))
 		}
 		return nil
-	}
+	})
 }
 
 // ExactArgs returns an error if there are not exactly n args.
 func ExactArgs(n int) PositionalArgs {
-	return func(cmd *Command, args []string) error {
+	return countedArgs(n, n, func(cmd *Command, args []string) error {
 		if len(args) != n {
 			return fmt.Errorf("accepts %d arg(s), received %d", n, len(args))
 		}
 		return nil
-	}
+	})
 }
 
 // ExactValidArgs returns an error if
 // there are not exactly N positional args OR
 // there are any positional args that are not in the `ValidArgs` field of `Command`
 func ExactValidArgs(n int) PositionalArgs {
-	return func(cmd *Command, args []string) error {
+	return countedArgs(n, n, func(cmd *Command, args []string) error {
 		if err := ExactArgs(n)(cmd, args); err != nil {
 			return err
 		}
 		return OnlyValidArgs(cmd, args)
-	}
+	})
 }
 
 // RangeArgs returns an error if the number of args is not within the expected range.
 func RangeArgs(min int, max int) PositionalArgs {
-	return func(cmd *Command, args []string) error {
+	return countedArgs(min, max, func(cmd *Command, args []string) error {
 		if len(args) < min || len(args) > max {
 			return fmt.Errorf("accepts between %d and %d arg(s), received %d", min, max, len(args))
 		}
 		return nil
-	}
+	})
 }
@@ -69,6 +69,22 @@ func TestOnlyValidArgsWithInvalidArgs(t *testing.T) {
 	}
 }
 
+func TestOnlyValidArgsWithDescriptions(t *testing.T) {
+	c := &Command{
+		Use:       "c",
+		Args:      OnlyValidArgs,
+		ValidArgs: []string{"one\tThe first one", "two\tThe second one"},
+		Run:       emptyRun,
+	}
+
+	if _, err := executeCommand(c, "one", "two"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := executeCommand(c, "one\tThe first one"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
 func TestArbitraryArgs(t *testing.T) {
 	c := &Command{Use: "c", Args: ArbitraryArgs, Run: emptyRun}
 	output, err := executeCommand(c, "a", "b")
@@ -285,3 +301,38 @@ func TestChildTakesArgs(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
+
+func TestArgCounts(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	tests := []struct {
+		cmd           *Command
+		min, max      int
+		expectedKnown bool
+	}{
+		{&Command{Use: "c"}, 0, -1, true},
+		{&Command{Use: "c", Args: NoArgs}, 0, 0, true},
+		{&Command{Use: "c", Args: ArbitraryArgs}, 0, -1, true},
+		{&Command{Use: "c", Args: ExactArgs(2)}, 2, 2, true},
+		{&Command{Use: "c", Args: ExactValidArgs(1)}, 1, 1, true},
+		{&Command{Use: "c", Args: RangeArgs(1, 3)}, 1, 3, true},
+		{&Command{Use: "c", Args: MinimumNArgs(2)}, 2, -1, true},
+		{&Command{Use: "c", Args: MaximumNArgs(4)}, 0, 4, true},
+		{&Command{Use: "c", Args: OnlyValidArgs}, 0, -1, false},
+		{&Command{Use: "c", Args: OnlyValidArgs, Annotations: map[string]string{ArgCountAnnotation: "1"}}, 1, 1, true},
+		{&Command{Use: "c", Args: OnlyValidArgs, Annotations: map[string]string{ArgCountAnnotation: "1-3"}}, 1, 3, true},
+		{&Command{Use: "c", Args: OnlyValidArgs, Annotations: map[string]string{ArgCountAnnotation: "2-"}}, 2, -1, true},
+		{&Command{Use: "c", Args: OnlyValidArgs, Annotations: map[string]string{ArgCountAnnotation: "3-1"}}, 0, -1, false},
+		{&Command{Use: "c", Args: OnlyValidArgs, Annotations: map[string]string{ArgCountAnnotation: "many"}}, 0, -1, false},
+	}
+	for i, tc := range tests {
+		min, max, ok := tc.cmd.ArgCounts()
+		if min != tc.min || max != tc.max || ok != tc.expectedKnown {
+			t.Errorf("%d: expected %d, %d, %v, got %d, %d, %v", i, tc.min, tc.max, tc.expectedKnown, min, max, ok)
+		}
+	}
+
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+	if min, max, ok := rootCmd.ArgCounts(); min != 0 || max != 0 || !ok {
+		t.Errorf("Expected a root with subcommands to take no args, got %d, %d, %v", min, max, ok)
+	}
+}
@@ -49,6 +49,9 @@ __%[1]s_debug_command_state() {
   flags_completion = [${flags_completion[@]}]
   must_have_one_flag = [${must_have_one_flag[@]}]
   must_have_one_noun = [${must_have_one_noun[@]}]
+  repeatable_flags = [${repeatable_flags[@]}]
+  mutually_exclusive_flags = [${mutually_exclusive_flags[@]}]
+  used_flags = [${used_flags[@]}]
   noun_aliases= [${noun_aliases[@]}]
   aliashash = keys[${!aliashash[@]}] values[${aliashash[@]}]"
 }
@@ -88,6 +91,53 @@ __%[1]s_contains_word()
     return 1
 }
 
+# Sets long_flag to the long form of the flag $1, as it is in the flags array
+__%[1]s_long_flag()
+{
+    local shorthand
+    long_flag=$1
+    for shorthand in "${flag_shorthands[@]}"; do
+        if [[ ${shorthand%% *} = "$1" ]]; then
+            long_flag=${shorthand#* }
+            return
+        fi
+    done
+}
+
+# Succeeds if the flag $1 can be offered: it was not used yet, unless it is
+# repeatable, and no flag that is mutually exclusive with it was used
+__%[1]s_flag_available()
+{
+    local long_flag group other
+    __%[1]s_long_flag "$1"
+    if __%[1]s_contains_word "${long_flag}" "${used_flags[@]}" &&
+        ! __%[1]s_contains_word "${long_flag}" "${repeatable_flags[@]}"; then
+        return 1
+    fi
+    for group in "${mutually_exclusive_flags[@]}"; do
+        read -ra group <<< "${group}"
+        __%[1]s_contains_word "${long_flag}" "${group[@]}" || continue
+        for other in "${group[@]}"; do
+            if [[ ${other} != "${long_flag}" ]] && __%[1]s_contains_word "${other}" "${used_flags[@]}"; then
+                return 1
+            fi
+        done
+    done
+    return 0
+}
+
+# Sets available_flags to the flags in "$@" that can be offered
+__%[1]s_available_flags()
+{
+    local flag
+    available_flags=()
+    for flag in "$@"; do
+        if __%[1]s_flag_available "${flag}"; then
+            available_flags+=("${flag}")
+        fi
+    done
+}
+
 # Called when the cursor word (i.e. the word to be completed) is parsed (c==cword)
 __%[1]s_handle_reply()
 {
@@ -97,13 +147,13 @@ __%[1]s_handle_reply()
             if [[ $(type -t compopt) = "builtin" ]]; then
                 compopt -o nospace
             fi
-            local allflags
-            if [ ${#must_have_one_flag[@]} -ne 0 ]; then
-                allflags=("${must_have_one_flag[@]}")
-            else
-                allflags=("${flags[*]} ${two_word_flags[*]}")
+            # required flags that were not set yet come first
+            local available_flags
+            __%[1]s_available_flags "${must_have_one_flag[@]}"
+            if [ ${#available_flags[@]} -eq 0 ]; then
+                __%[1]s_available_flags "${flags[@]}"
             fi
-            COMPREPLY=( $(compgen -W "${allflags[*]}" -- "$cur") )
+            COMPREPLY=( $(compgen -W "${available_flags[*]}" -- "$cur") )
             if [[ $(type -t compopt) = "builtin" ]]; then
                 [[ "${COMPREPLY[0]}" == *= ]] || compopt +o nospace
             fi
@@ -154,7 +204,9 @@ __%[1]s_handle_reply()
         completions=("${must_have_one_noun[@]}")
     fi
     if [[ ${#must_have_one_flag[@]} -ne 0 ]]; then
-        completions+=("${must_have_one_flag[@]}")
+        local available_flags
+        __%[1]s_available_flags "${must_have_one_flag[@]}"
+        completions+=("${available_flags[@]}")
     fi
     COMPREPLY=( $(compgen -W "${completions[*]}" -- "$cur") )
 
@@ -198,11 +250,15 @@ __%[1]s_handle_subdirs_in_dir_flag()
     pushd "${dir}" >/dev/null 2>&1 && _filedir -d && popd >/dev/null 2>&1
 }
 
+__%[1]s_handle_enum_flag()
+{
+    COMPREPLY=( $(compgen -W "$*" -- "$cur") )
+}
+
 __%[1]s_handle_flag()
 {
     __%[1]s_debug_func_entry "${FUNCNAME[0]}"
 
-    # if a command required a flag, and we found it, unset must_have_one_flag()
     local flagname=${words[c]}
     local flagvalue
     # if the word contained an =
@@ -211,9 +267,22 @@ __%[1]s_handle_flag()
         flagname=${flagname%%=*} # strip everything after the =
         flagname="${flagname}=" # but put the = back
     fi
-    if __%[1]s_contains_word "${flagname}" "${must_have_one_flag[@]}"; then
-        must_have_one_flag=()
+
+    # remember the flag, so it isn't offered again, and if the command
+    # required it, remove it from must_have_one_flag()
+    local long_flag used required required_flags=()
+    if __%[1]s_contains_word "${flagname}=" "${two_word_flags[@]}"; then
+        __%[1]s_long_flag "${flagname}="
+    else
+        __%[1]s_long_flag "${flagname}"
     fi
+    used=${long_flag}
+    used_flags+=("${used}")
+    for required in "${must_have_one_flag[@]}"; do
+        __%[1]s_long_flag "${required}"
+        [[ ${long_flag} = "${used}" ]] || required_flags+=("${required}")
+    done
+    must_have_one_flag=("${required_flags[@]}")
 
     # if you set a flag which only applies to this command, don't show subcommands
     if __%[1]s_contains_word "${flagname}" "${local_nonpersistent_flags[@]}"; then
@@ -330,6 +399,10 @@ func writePostscript(buf *bytes.Buffer, name string) {
     local commands=("%[1]s")
     local must_have_one_flag=()
     local must_have_one_noun=()
+    local repeatable_flags=()
+    local mutually_exclusive_flags=()
+    local flag_shorthands=()
+    local used_flags=()
     local last_command
     local nouns=()
 
@@ -358,6 +431,9 @@ func writeResets(buf *bytes.Buffer) {
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
 `)
 }
 
@@ -382,7 +458,6 @@ func writeCommandFunctions(buf *bytes.Buffer, cmd *Command) {
 	writeResets(buf)
 	writeCommands(buf, cmd)
 	writeFlags(buf, cmd)
-	//writeRequiredFlag(buf, cmd)
 	writeValidArgs(buf, cmd)
 	writeArgAliases(buf, cmd)
 	buf.WriteString(fmt.Sprintf("    __%s_debug_command_state \"${FUNCNAME[0]}\"\n}\n\n", cmd.Root().Name()))
@@ -408,6 +483,9 @@ func writeCmdAliases(buf *bytes.Buffer, cmd *Command) {
 
 	buf.WriteString(fmt.Sprint(`    if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then`, "\n"))
 	for _, value := range cmd.Aliases {
+		if cmd.IsDeprecatedAlias(value) {
+			continue
+		}
 		buf.WriteString(fmt.Sprintf("        command_aliases+=(%q)\n", value))
 		buf.WriteString(fmt.Sprintf("        aliashash[%q]=%q\n", value, cmd.Name()))
 	}
@@ -489,8 +567,8 @@ func writeLocalNonPersistentFlag(buf *bytes.Buffer, flag *pflag.Flag) {
 func writeFlags(buf *bytes.Buffer, cmd *Command) {
 	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
 
-		// Ignore hidden or deprecated flags
-		if flag.Hidden || flag.Deprecated != "" {
+		// Ignore hidden, deprecated or disabled experimental flags
+		if flag.Hidden || flag.Deprecated != "" || !cmd.FlagAllowed(flag) {
 			return
 		}
 
@@ -507,6 +585,17 @@ func writeFlags(buf *bytes.Buffer, cmd *Command) {
 			writeFlag(buf, flag, "local_nonpersistent_flags")
 		}
 
+		// Flags that may be given more than once are in 'repeatable_flags'
+		if FlagRepeatable(flag) {
+			writeFlag(buf, flag, "repeatable_flags")
+		}
+
+		// Shorthands are mapped to the long form of their flag in 'flag_shorthands'
+		if flag.Shorthand != "" {
+			long, short := bashFlagForms(flag)
+			buf.WriteString(fmt.Sprintf("    flag_shorthands+=(%q)\n", short+" "+long))
+		}
+
 		// Further categorizations of flags are made through annotations
 		for key, value := range flag.Annotations {
 			switch key {
@@ -559,6 +648,17 @@ func writeFlags(buf *bytes.Buffer, cmd *Command) {
 					buf.WriteString(fmt.Sprintf("    flags_completion+=(%q)\n", bashCode))
 				}
 
+			// Flags whose value must be one of the values set by MarkFlagEnum
+			case FlagEnumAnnotation:
+				// Flag goes to 'flags_with_completion'
+				writeFlag(buf, flag, "flags_with_completion")
+				// Code for completion of the value goes to 'flags_completion'
+				bashCode := fmt.Sprintf("__%s_handle_enum_flag ", cmd.Root().Name()) + strings.Join(value, " ")
+				buf.WriteString(fmt.Sprintf("    flags_completion+=(%q)\n", bashCode))
+				if flag.Shorthand != "" {
+					buf.WriteString(fmt.Sprintf("    flags_completion+=(%q)\n", bashCode))
+				}
+
 			// Flags that are required for THIS command
 			case BashCompOneRequiredFlag:
 				if cmd.NonInheritedFlags().Lookup(flag.Name) != nil {
@@ -567,6 +667,7 @@ func writeFlags(buf *bytes.Buffer, cmd *Command) {
 			}
 		}
 	})
+	writeMutuallyExclusiveFlags(buf, cmd)
 }
 
 func writeFlag(buf *bytes.Buffer, flag *pflag.Flag, category string) {
@@ -585,6 +686,37 @@ func writeFlag(buf *bytes.Buffer, flag *pflag.Flag, category string) {
 	}
 }
 
+// bashFlagForms returns the long and short forms of flag as they are in the
+// flags array, with a = if the flag requires a value.
+func bashFlagForms(flag *pflag.Flag) (long, short string) {
+	long = "--" + flag.Name
+	if flag.Shorthand != "" {
+		short = "-" + flag.Shorthand
+	}
+	if flag.NoOptDefVal == "" {
+		long += "="
+		if short != "" {
+			short += "="
+		}
+	}
+	return long, short
+}
+
+// writeMutuallyExclusiveFlags writes each group of mutually exclusive flags
+// of cmd to 'mutually_exclusive_flags', as the long forms of its flags.
+func writeMutuallyExclusiveFlags(buf *bytes.Buffer, cmd *Command) {
+	for _, group := range cmd.flagGroups {
+		var forms []string
+		for _, name := range group {
+			if f := cmd.Flags().Lookup(name); f != nil {
+				long, _ := bashFlagForms(f)
+				forms = append(forms, long)
+			}
+		}
+		buf.WriteString(fmt.Sprintf("    mutually_exclusive_flags+=(%q)\n", strings.Join(forms, " ")))
+	}
+}
+
 /*func writeFlags(buf *bytes.Buffer, cmd *Command) {
 	localNonPersistentFlags := cmd.LocalNonPersistentFlags()
 	cmd.NonInheritedFlags().VisitAll(func(flag *pflag.Flag) {
@@ -610,35 +742,15 @@ func writeFlag(buf *bytes.Buffer, flag *pflag.Flag, category string) {
 	})
 
 	buf.WriteString("\n")
-}
-
-func writeRequiredFlag(buf *bytes.Buffer, cmd *Command) {
-	flags := cmd.NonInheritedFlags()
-	flags.VisitAll(func(flag *pflag.Flag) {
-		if nonCompletableFlag(flag) {
-			return
-		}
-		for key := range flag.Annotations {
-			switch key {
-			case BashCompOneRequiredFlag:
-				format := "    must_have_one_flag+=(\"--%s"
-				if flag.Value.Type() != "bool" {
-					format += "="
-				}
-				format += "\")\n"
-				buf.WriteString(fmt.Sprintf(format, flag.Name))
-
-				if len(flag.Shorthand) > 0 {
-					buf.WriteString(fmt.Sprintf("    must_have_one_flag+=(\"-%s\")\n", flag.Shorthand))
-				}
-			}
-		}
-	})
 }*/
 
 func writeValidArgs(buf *bytes.Buffer, cmd *Command) {
 	sort.Sort(sort.StringSlice(cmd.ValidArgs))
-	for _, value := range cmd.ValidArgs {
+	for _, arg := range cmd.ValidArgs {
+		value := validArgValue(arg)
+		if cmd.IsDeprecatedValidArg(value) {
+			continue
+		}
 		buf.WriteString(fmt.Sprintf("    must_have_one_noun+=(%q)\n", value))
 	}
 }
@@ -0,0 +1,218 @@
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// GenBashCompletionV2 generates a bash completion file and writes it to the
+// passed writer. Unlike the script of GenBashCompletion, it doesn't describe
+// the commands and flags of c; it asks the program for the completion
+// candidates through the hidden ShellCompRequestCmd command each time the
+// user presses TAB, so it stays small and always matches the program. With
+// includeDesc, the descriptions of the candidates are shown when they are
+// listed.
+func (c *Command) GenBashCompletionV2(w io.Writer, includeDesc bool) error {
+	buf := new(bytes.Buffer)
+	writeBashV2Script(buf, c.Name(), includeDesc)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// GenBashCompletionFileV2 generates a bash completion file with
+// GenBashCompletionV2.
+func (c *Command) GenBashCompletionFileV2(filename string, includeDesc bool) error {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	return c.GenBashCompletionV2(outFile, includeDesc)
+}
+
+func writeBashV2Script(buf *bytes.Buffer, name string, includeDesc bool) {
+	requestCmd := ShellCompNoDescRequestCmd
+	if includeDesc {
+		requestCmd = ShellCompRequestCmd
+	}
+	funcName := strings.Replace(name, ":", "__", -1)
+
+	buf.WriteString(fmt.Sprintf("# bash completion V2 for %-33s -*- shell-script -*-\n", name))
+	buf.WriteString(fmt.Sprintf(`
+__%[1]s_debug()
+{
+    if [[ -n ${BASH_COMP_DEBUG_FILE:-} ]]; then
+        echo "$*" >> "${BASH_COMP_DEBUG_FILE}"
+    fi
+}
+
+# Sets words, cword and cur to the words of the command line up to the
+# cursor, without splitting them at "=" or ":" like bash does.
+__%[1]s_get_words()
+{
+    if declare -F _get_comp_words_by_ref >/dev/null 2>&1; then
+        _get_comp_words_by_ref -n "=:" cur words cword
+        return
+    fi
+
+    local line="${COMP_LINE:0:COMP_POINT}"
+    read -ra words <<< "$line"
+    if [[ ${#words[@]} -eq 0 || "$line" == *[[:space:]] ]]; then
+        words+=("")
+    fi
+    cword=$(( ${#words[@]} - 1 ))
+    cur="${words[cword]}"
+}
+
+# Adds the lines of the output of a command to COMPREPLY.
+__%[1]s_add_lines()
+{
+    local line
+    while IFS= read -r line; do
+        [[ -n "$line" ]] && COMPREPLY+=("$line")
+    done < <("$@")
+}
+
+__%[1]s_handle_file_ext()
+{
+    local exts="$1"
+    if declare -F _filedir >/dev/null 2>&1; then
+        _filedir "@($exts)"
+        return
+    fi
+
+    local extglob_off=0
+    if ! shopt -q extglob; then
+        extglob_off=1
+        shopt -s extglob
+    fi
+    __%[1]s_add_lines compgen -f -X "!*.@($exts)" -- "$cur"
+    __%[1]s_add_lines compgen -d -- "$cur"
+    if (( extglob_off )); then
+        shopt -u extglob
+    fi
+    compopt -o filenames 2>/dev/null
+}
+
+__%[1]s_handle_dirs()
+{
+    local dir="$1"
+    if [[ -n "$dir" ]]; then
+        pushd "$dir" >/dev/null 2>&1 || return
+    fi
+    if declare -F _filedir >/dev/null 2>&1; then
+        _filedir -d
+    else
+        __%[1]s_add_lines compgen -d -- "$cur"
+        compopt -o filenames 2>/dev/null
+    fi
+    if [[ -n "$dir" ]]; then
+        popd >/dev/null 2>&1
+    fi
+}
+
+# Sets COMPREPLY to the candidates, in the form "value<TAB>description".
+# The descriptions are shown when there are several candidates to list.
+__%[1]s_handle_candidates()
+{
+    local candidates=("$@")
+
+    # Bash replaces the text after the last "=" or ":" in COMP_WORDBREAKS
+    # only, so that is all the candidates may contain.
+    local breaks="${COMP_WORDBREAKS//[^=:]/}" prefix=""
+    if [[ -n "$breaks" && "$cur" == *[$breaks]* ]]; then
+        prefix="${cur%%"${cur##*[$breaks]}"}"
+    fi
+
+    if (( ${#candidates[@]} == 1 )) || [[ ${COMP_TYPE:-63} == 9 ]]; then
+        # A single candidate is inserted, and the first TAB only inserts the
+        # common prefix of several, so neither needs descriptions.
+        local candidate
+        for candidate in "${candidates[@]}"; do
+            candidate="${candidate%%%%$'\t'*}"
+            COMPREPLY+=("${candidate#"$prefix"}")
+        done
+        return
+    fi
+
+    local candidate value width=0
+    for candidate in "${candidates[@]}"; do
+        value="${candidate%%%%$'\t'*}"
+        value="${value#"$prefix"}"
+        (( ${#value} > width )) && width=${#value}
+    done
+
+    local desc line max=$(( ${COLUMNS:-80} - 1 ))
+    for candidate in "${candidates[@]}"; do
+        value="${candidate%%%%$'\t'*}"
+        value="${value#"$prefix"}"
+        if [[ "$candidate" == *$'\t'* ]]; then
+            desc="${candidate#*$'\t'}"
+            printf -v line "%%-${width}s  (%%s)" "$value" "$desc"
+            if (( ${#line} > max )); then
+                line="${line:0:max-4}...)"
+            fi
+        else
+            line="$value"
+        fi
+        COMPREPLY+=("$line")
+    done
+}
+
+__start_%[1]s()
+{
+    local cur words cword
+    COMPREPLY=()
+    __%[1]s_get_words
+
+    local out
+    out=$("${words[0]}" %[2]s "${words[@]:1:cword-1}" "$cur" 2>/dev/null)
+    __%[1]s_debug "${FUNCNAME[0]}: ${words[*]:0:cword} '$cur': $out"
+
+    # The last line of the output is a colon followed by the directive.
+    local directive="${out##*:}"
+    out="${out%%:*}"
+    if [[ ! "$directive" =~ ^[0-9]+$ ]] || (( directive & %[3]d )); then
+        return
+    fi
+
+    local candidates=() line
+    while IFS= read -r line; do
+        [[ -n "$line" ]] && candidates+=("$line")
+    done <<< "$out"
+
+    if (( directive & %[4]d )); then
+        local IFS="|"
+        __%[1]s_handle_file_ext "${candidates[*]}"
+        return
+    fi
+    if (( directive & %[5]d )); then
+        __%[1]s_handle_dirs "${candidates[0]}"
+        return
+    fi
+
+    if (( ${#candidates[@]} > 0 )); then
+        __%[1]s_handle_candidates "${candidates[@]}"
+    fi
+    if (( directive & %[6]d )); then
+        compopt -o nospace 2>/dev/null
+    fi
+    if (( directive & %[7]d )); then
+        compopt +o default 2>/dev/null
+    fi
+}
+
+if [[ $(type -t compopt) = "builtin" ]]; then
+    complete -o default -F __start_%[1]s %[8]s
+else
+    complete -o default -o nospace -F __start_%[1]s %[8]s
+fi
+
+# ex: ts=4 sw=4 et filetype=sh
+`, funcName, requestCmd, ShellCompDirectiveError, ShellCompDirectiveFilterFileExt, ShellCompDirectiveFilterDirs,
+		ShellCompDirectiveNoSpace, ShellCompDirectiveNoFileComp, name))
+}
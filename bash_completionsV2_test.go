@@ -0,0 +1,205 @@
+package cobra
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// bashV2HelperEnv makes TestBashCompletionV2Helper run the test command,
+// so that the completion script can call the test binary as the program.
+const bashV2HelperEnv = "COBRA_TEST_BASH_V2_HELPER"
+
+// newBashV2TestRoot returns the program that TestBashCompletionV2Helper runs
+// for the completion scripts, so the tests that complete through it share it.
+func newBashV2TestRoot() *Command {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	getCmd := &Command{
+		Use:       "get",
+		Short:     "Get resources",
+		ValidArgs: []string{"pod\tA group of containers", "service\tA network service"},
+		Run:       emptyRun,
+	}
+	getCmd.AddOutputFlag()
+	getCmd.Flags().String("file", "", "input file")
+	getCmd.MarkFlagFilename("file", "yaml", "json")
+	getCmd.Flags().String("dir", "", "output directory")
+	getCmd.Flags().SetAnnotation("dir", BashCompSubdirsInDir, []string{})
+	rootCmd.AddCommand(getCmd, &Command{Use: "gc", Short: "Collect garbage", Run: emptyRun})
+	rootCmd.AddCommand(&Command{Use: "version", Short: "Print the version", Run: emptyRun})
+	return rootCmd
+}
+
+func TestBashCompletionV2Helper(t *testing.T) {
+	if os.Getenv(bashV2HelperEnv) != "1" {
+		return
+	}
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	rootCmd := newBashV2TestRoot()
+	rootCmd.SetArgs(args)
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestCompleteCmd(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{ShellCompRequestCmd, ""},
+			"completion\tGenerate the autocompletion script for a shell\n" +
+				"gc\tCollect garbage\nget\tGet resources\n" +
+				"help\tHelp about any command\nversion\tPrint the version\n:4\n"},
+		{[]string{ShellCompNoDescRequestCmd, "g"}, "gc\nget\n:4\n"},
+		{[]string{ShellCompRequestCmd, "get", "s"}, "service\tA network service\n:4\n"},
+		{[]string{ShellCompRequestCmd, "get", "--output=j"}, "--output=json\n:4\n"},
+		{[]string{ShellCompRequestCmd, "get", "-o", ""}, "json\ntable\ntemplate\nyaml\n:4\n"},
+		{[]string{ShellCompRequestCmd, "get", "--file", ""}, "yaml\njson\n:8\n"},
+		{[]string{ShellCompRequestCmd, "get", "--dir", ""}, ":16\n"},
+		{[]string{ShellCompRequestCmd, "get", "--template", ""}, ":0\n"},
+		{[]string{ShellCompRequestCmd, "version", ""}, ":0\n"},
+		{[]string{ShellCompRequestCmd, "get", "--col"}, "--columns\tcolumns of the table output format\n:4\n"},
+	}
+	for _, tc := range tests {
+		rootCmd := newBashV2TestRoot()
+		rootCmd.InitDefaultCompletionCmd()
+		output, err := executeCommand(rootCmd, tc.args...)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.args, err)
+			continue
+		}
+		if output != tc.expected {
+			t.Errorf("%q: expected:\n%q\ngot:\n%q", tc.args, tc.expected, output)
+		}
+	}
+}
+
+func TestCompleteCmdUnknownCommand(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "get", Run: emptyRun})
+	output, err := executeCommand(rootCmd, ShellCompRequestCmd, "unknown", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output != ":1\n" {
+		t.Errorf("Expected the error directive, got %q", output)
+	}
+}
+
+func TestBashCompletionV2(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "get", Run: emptyRun})
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletionV2(buf, true)
+	output := buf.String()
+
+	check(t, output, "__start_app()")
+	check(t, output, `"${words[0]}" __complete `)
+	check(t, output, "complete -o default -F __start_app app")
+
+	buf.Reset()
+	rootCmd.GenBashCompletionV2(buf, false)
+	check(t, buf.String(), `"${words[0]}" __completeNoDesc `)
+
+	// If available, run shellcheck against the script.
+	if err := exec.Command("which", "shellcheck").Run(); err != nil {
+		return
+	}
+	if err := runShellCheck(output); err != nil {
+		t.Fatalf("shellcheck failed: %v", err)
+	}
+}
+
+// completeInBash sources the completion script of newBashV2TestRoot in bash,
+// with the test binary standing in for the program, and returns COMPREPLY
+// for line, with the cursor at its end.
+func completeInBash(t *testing.T, script, dir, line string) []string {
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", `
+app() { "$TESTBIN" -test.run='^TestBashCompletionV2Helper$' -- "$@"; }
+source "$SCRIPT"
+COMP_LINE="$LINE"
+COMP_POINT=${#COMP_LINE}
+COLUMNS=60
+__start_app
+printf '%s\n' "${COMPREPLY[@]}"
+`)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), bashV2HelperEnv+"=1", "TESTBIN="+os.Args[0], "SCRIPT="+script, "LINE="+line)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%q: bash failed: %v\n%s", line, err, out)
+	}
+	return strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+}
+
+func TestBashCompletionV2InBash(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Completion scripts are only run on Linux")
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "cobra-bash-v2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	for _, name := range []string{"a.yaml", "b.json", "c.txt"} {
+		if err := ioutil.WriteFile(filepath.Join(tmpdir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tmpdir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	script := filepath.Join(tmpdir, "app.bash")
+	if err := newBashV2TestRoot().GenBashCompletionFileV2(script, true); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"app g", []string{"gc   (Collect garbage)", "get  (Get resources)"}},
+		{"app ge", []string{"get"}},
+		{"app get ", []string{"pod      (A group of containers)", "service  (A network service)"}},
+		{"app get --output=j", []string{"json"}},
+		{"app get -o t", []string{"table", "template"}},
+		{"app get --file ", []string{"a.yaml", "b.json", "sub"}},
+		{"app get --dir ", []string{"sub"}},
+		{"app get --col", []string{"--columns"}},
+		{"app get --", []string{
+			"--columns   (columns of the table output format)",
+			"--dir       (output directory)",
+			"--file      (input file)",
+			"--help      (help for get)",
+			"--output    (output format (one of json, yaml, table, t...)",
+			"--template  (Go template for the template output format)",
+		}},
+	}
+	for _, tc := range tests {
+		got := completeInBash(t, script, tmpdir, tc.line)
+		// File names are listed in directory order.
+		sort.Strings(got)
+		if strings.Join(got, "\n") != strings.Join(tc.expected, "\n") {
+			t.Errorf("%q: expected:\n%q\ngot:\n%q", tc.line, tc.expected, got)
+		}
+	}
+}
@@ -27,6 +27,9 @@ __root_debug_command_state() {
   flags_completion = [${flags_completion[@]}]
   must_have_one_flag = [${must_have_one_flag[@]}]
   must_have_one_noun = [${must_have_one_noun[@]}]
+  repeatable_flags = [${repeatable_flags[@]}]
+  mutually_exclusive_flags = [${mutually_exclusive_flags[@]}]
+  used_flags = [${used_flags[@]}]
   noun_aliases= [${noun_aliases[@]}]
   aliashash = keys[${!aliashash[@]}] values[${aliashash[@]}]"
 }
@@ -66,6 +69,53 @@ __root_contains_word()
     return 1
 }
 
+# Sets long_flag to the long form of the flag $1, as it is in the flags array
+__root_long_flag()
+{
+    local shorthand
+    long_flag=$1
+    for shorthand in "${flag_shorthands[@]}"; do
+        if [[ ${shorthand% *} = "$1" ]]; then
+            long_flag=${shorthand#* }
+            return
+        fi
+    done
+}
+
+# Succeeds if the flag $1 can be offered: it was not used yet, unless it is
+# repeatable, and no flag that is mutually exclusive with it was used
+__root_flag_available()
+{
+    local long_flag group other
+    __root_long_flag "$1"
+    if __root_contains_word "${long_flag}" "${used_flags[@]}" &&
+        ! __root_contains_word "${long_flag}" "${repeatable_flags[@]}"; then
+        return 1
+    fi
+    for group in "${mutually_exclusive_flags[@]}"; do
+        read -ra group <<< "${group}"
+        __root_contains_word "${long_flag}" "${group[@]}" || continue
+        for other in "${group[@]}"; do
+            if [[ ${other} != "${long_flag}" ]] && __root_contains_word "${other}" "${used_flags[@]}"; then
+                return 1
+            fi
+        done
+    done
+    return 0
+}
+
+# Sets available_flags to the flags in "$@" that can be offered
+__root_available_flags()
+{
+    local flag
+    available_flags=()
+    for flag in "$@"; do
+        if __root_flag_available "${flag}"; then
+            available_flags+=("${flag}")
+        fi
+    done
+}
+
 # Called when the cursor word (i.e. the word to be completed) is parsed (c==cword)
 __root_handle_reply()
 {
@@ -75,13 +125,13 @@ __root_handle_reply()
             if [[ $(type -t compopt) = "builtin" ]]; then
                 compopt -o nospace
             fi
-            local allflags
-            if [ ${#must_have_one_flag[@]} -ne 0 ]; then
-                allflags=("${must_have_one_flag[@]}")
-            else
-                allflags=("${flags[*]} ${two_word_flags[*]}")
+            # required flags that were not set yet come first
+            local available_flags
+            __root_available_flags "${must_have_one_flag[@]}"
+            if [ ${#available_flags[@]} -eq 0 ]; then
+                __root_available_flags "${flags[@]}"
             fi
-            COMPREPLY=( $(compgen -W "${allflags[*]}" -- "$cur") )
+            COMPREPLY=( $(compgen -W "${available_flags[*]}" -- "$cur") )
             if [[ $(type -t compopt) = "builtin" ]]; then
                 [[ "${COMPREPLY[0]}" == *= ]] || compopt +o nospace
             fi
@@ -132,7 +182,9 @@ __root_handle_reply()
         completions=("${must_have_one_noun[@]}")
     fi
     if [[ ${#must_have_one_flag[@]} -ne 0 ]]; then
-        completions+=("${must_have_one_flag[@]}")
+        local available_flags
+        __root_available_flags "${must_have_one_flag[@]}"
+        completions+=("${available_flags[@]}")
     fi
     COMPREPLY=( $(compgen -W "${completions[*]}" -- "$cur") )
 
@@ -176,11 +228,15 @@ __root_handle_subdirs_in_dir_flag()
     pushd "${dir}" >/dev/null 2>&1 && _filedir -d && popd >/dev/null 2>&1
 }
 
+__root_handle_enum_flag()
+{
+    COMPREPLY=( $(compgen -W "$*" -- "$cur") )
+}
+
 __root_handle_flag()
 {
     __root_debug_func_entry "${FUNCNAME[0]}"
 
-    # if a command required a flag, and we found it, unset must_have_one_flag()
     local flagname=${words[c]}
     local flagvalue
     # if the word contained an =
@@ -189,9 +245,22 @@ __root_handle_flag()
         flagname=${flagname%=*} # strip everything after the =
         flagname="${flagname}=" # but put the = back
     fi
-    if __root_contains_word "${flagname}" "${must_have_one_flag[@]}"; then
-        must_have_one_flag=()
+
+    # remember the flag, so it isn't offered again, and if the command
+    # required it, remove it from must_have_one_flag()
+    local long_flag used required required_flags=()
+    if __root_contains_word "${flagname}=" "${two_word_flags[@]}"; then
+        __root_long_flag "${flagname}="
+    else
+        __root_long_flag "${flagname}"
     fi
+    used=${long_flag}
+    used_flags+=("${used}")
+    for required in "${must_have_one_flag[@]}"; do
+        __root_long_flag "${required}"
+        [[ ${long_flag} = "${used}" ]] || required_flags+=("${required}")
+    done
+    must_have_one_flag=("${required_flags[@]}")
 
     # if you set a flag which only applies to this command, don't show subcommands
     if __root_contains_word "${flagname}" "${local_nonpersistent_flags[@]}"; then
@@ -300,6 +369,9 @@ _root_cmd__colon()
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
 
     noun_aliases=()
     __root_debug_command_state "${FUNCNAME[0]}"
@@ -318,6 +390,9 @@ _root_echo_times()
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
 
     must_have_one_noun+=("four")
     must_have_one_noun+=("one")
@@ -340,6 +415,9 @@ _root_echo()
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
     commands+=("times")
 
     flags+=("--config=")
@@ -373,6 +451,9 @@ _root_print()
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
 
     noun_aliases=()
     __root_debug_command_state "${FUNCNAME[0]}"
@@ -391,6 +472,9 @@ _root_root_command()
     flags_completion=()
     must_have_one_flag=()
     must_have_one_noun=()
+    repeatable_flags=()
+    mutually_exclusive_flags=()
+    flag_shorthands=()
     commands+=("cmd:colon")
     commands+=("echo")
     if [[ -z "${BASH_VERSION}" || "${BASH_VERSINFO[0]}" -gt 3 ]]; then
@@ -420,6 +504,7 @@ _root_root_command()
     two_word_flags+=("-i=")
     local_nonpersistent_flags+=("--introot=")
     local_nonpersistent_flags+=("-i=")
+    flag_shorthands+=("-i= --introot=")
     must_have_one_flag+=("--introot=")
     must_have_one_flag+=("-i=")
     flags+=("--persistent-filename=")
@@ -438,10 +523,12 @@ _root_root_command()
     two_word_flags+=("-t=")
     local_nonpersistent_flags+=("--two=")
     local_nonpersistent_flags+=("-t=")
+    flag_shorthands+=("-t= --two=")
     flags+=("--two-w-default")
     flags+=("-T")
     local_nonpersistent_flags+=("--two-w-default")
     local_nonpersistent_flags+=("-T")
+    flag_shorthands+=("-T --two-w-default")
     must_have_one_noun+=("node")
     must_have_one_noun+=("pod")
     must_have_one_noun+=("replicationcontroller")
@@ -478,6 +565,10 @@ __start_root()
     local commands=("root")
     local must_have_one_flag=()
     local must_have_one_noun=()
+    local repeatable_flags=()
+    local mutually_exclusive_flags=()
+    local flag_shorthands=()
+    local used_flags=()
     local last_command
     local nouns=()
 
@@ -3,9 +3,13 @@ package cobra
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -269,3 +273,89 @@ func TestBashCompletionDeprecatedFlag(t *testing.T) {
 		t.Errorf("expected completion to not include %q flag: Got %v", flagName, output)
 	}
 }
+
+func TestBashCompletionValidArgsDescriptions(t *testing.T) {
+	c := &Command{Use: "c", ValidArgs: []string{"pod\tA group of containers", "node"}, Run: emptyRun}
+
+	buf := new(bytes.Buffer)
+	c.GenBashCompletion(buf)
+	output := buf.String()
+
+	check(t, output, `must_have_one_noun+=("pod")`)
+	check(t, output, `must_have_one_noun+=("node")`)
+	checkOmit(t, output, "A group of containers")
+}
+
+func TestBashCompletionUsedAndRequiredFlags(t *testing.T) {
+	rootCmd := newFlagGroupsTestRoot()
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletion(buf)
+	output := buf.String()
+
+	check(t, output, `must_have_one_flag+=("--output=")`)
+	check(t, output, `repeatable_flags+=("--tag=")`)
+	check(t, output, `flag_shorthands+=("-o= --output=")`)
+	check(t, output, `flag_shorthands+=("-v --verbose")`)
+	check(t, output, `mutually_exclusive_flags+=("--json --yaml")`)
+	checkNumOccurrences(t, output, "mutually_exclusive_flags+=", 1)
+
+	if runtime.GOOS != "linux" {
+		return
+	}
+	if _, err := exec.LookPath("bash"); err != nil {
+		return
+	}
+	tmpdir, err := ioutil.TempDir("", "cobra-bash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	script := filepath.Join(tmpdir, "app.bash")
+	if err := ioutil.WriteFile(script, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"app get -", []string{"--output=", "-o="}},
+		{"app get -o out -", []string{"--json", "--tag=", "--verbose", "--yaml", "-t=", "-v"}},
+		{"app get --output=out --yaml -v -", []string{"--tag=", "-t="}},
+		{"app get --output=out -t a --tag=b -", []string{"--json", "--tag=", "--verbose", "--yaml", "-t=", "-v"}},
+	}
+	for _, tc := range tests {
+		got := bashCompReply(t, script, tc.line)
+		if strings.Join(got, " ") != strings.Join(tc.expected, " ") {
+			t.Errorf("%q: expected %q, got %q", tc.line, tc.expected, got)
+		}
+	}
+}
+
+// bashCompReply sources the completion script of the app command in bash and
+// returns the sorted COMPREPLY for line, with the cursor at its end. It
+// stands in for _get_comp_words_by_ref, so bash-completion isn't needed.
+func bashCompReply(t *testing.T, script, line string) []string {
+	cmd := exec.Command("bash", "--norc", "--noprofile", "-c", `
+_get_comp_words_by_ref() {
+    cur=${COMP_WORDS[COMP_CWORD]}
+    prev=${COMP_WORDS[COMP_CWORD-1]}
+    words=("${COMP_WORDS[@]}")
+    cword=$COMP_CWORD
+}
+source "$SCRIPT"
+read -ra COMP_WORDS <<< "$LINE"
+[[ "$LINE" == *" " ]] && COMP_WORDS+=("")
+COMP_CWORD=$(( ${#COMP_WORDS[@]} - 1 ))
+__start_app 2>/dev/null
+printf '%s\n' "${COMPREPLY[@]}"
+`)
+	cmd.Env = append(os.Environ(), "SCRIPT="+script, "LINE="+line)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("%q: bash failed: %v", line, err)
+	}
+	reply := strings.Fields(string(out))
+	sort.Strings(reply)
+	return reply
+}
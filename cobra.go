@@ -38,6 +38,7 @@ var templateFuncs = template.FuncMap{
 }
 
 var initializers []func()
+var finalizers []func()
 
 // EnablePrefixMatching allows to set automatic prefix matching. Automatic prefix matching can be a dangerous thing
 // to automatically enable in CLI tools.
@@ -83,6 +84,28 @@ func OnInitialize(y ...func()) {
 	initializers = append(initializers, y...)
 }
 
+// OnFinalize sets the passed functions to be run when each command's
+// Execute method is done, after its post hooks, even if the command
+// failed or its context was cancelled.
+func OnFinalize(y ...func()) {
+	finalizers = append(finalizers, y...)
+}
+
+// ExitCode returns the exit code a program should terminate with after
+// Execute or ExecuteC returned err: 0 if err is nil, the result of its
+// ExitCode method if err has one, and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	if e, ok := err.(interface {
+		ExitCode() int
+	}); ok {
+		return e.ExitCode()
+	}
+	return 1
+}
+
 // FIXME Gt is unused by cobra and should be removed in a version 2. It exists only for compatibility with users of cobra.
 
 // Gt takes two types and checks whether the first type is greater than the second. In case of types Arrays, Chans,
@@ -155,7 +178,14 @@ func rpad(s string, padding int) string {
 
 // tmpl executes the given template text on data, writing the result to w.
 func tmpl(w io.Writer, text string, data interface{}) error {
+	return styledTmpl(w, text, data, nil)
+}
+
+// styledTmpl executes the given template text on data, like tmpl, with
+// the styling template functions using theme.
+func styledTmpl(w io.Writer, text string, data interface{}, theme *Theme) error {
 	t := template.New("top")
+	t.Funcs(themeFuncs(theme))
 	t.Funcs(templateFuncs)
 	template.Must(t.Parse(text))
 	return t.Execute(w, data)
@@ -1,6 +1,7 @@
 package cobra
 
 import (
+	"errors"
 	"testing"
 	"text/template"
 )
@@ -20,3 +21,24 @@ func TestAddTemplateFunctions(t *testing.T) {
 		t.Errorf("Expected UsageString: %v\nGot: %v", expected, got)
 	}
 }
+
+type exitCodeError int
+
+func (e exitCodeError) Error() string { return "exit code error" }
+func (e exitCodeError) ExitCode() int { return int(e) }
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, 0},
+		{errors.New("error"), 1},
+		{exitCodeError(3), 3},
+	}
+	for _, tc := range tests {
+		if got := ExitCode(tc.err); got != tc.expected {
+			t.Errorf("ExitCode(%v): expected %d, got %d", tc.err, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+// Package cobrahttp exposes a cobra command tree over HTTP, so that
+// graphical frontends and automation can drive a CLI without starting a
+// process for every command.
+//
+// A Handler serves two endpoints:
+//
+//	POST /execute   executes a command line, see Request and Response
+//	GET  /schema    describes the exposed commands and their flags
+//
+// Commands are only executed if they are exposed: a command is exposed if
+// its AnnotationExpose annotation, or that of its nearest ancestor that has
+// one, is "true". If none of them has the annotation, Handler.ExposeByDefault
+// decides.
+//
+// Requests are executed one at a time on the command tree itself, with the
+// flags reset to their defaults before each one (see
+// cobra.Command.ResetFlagsState), so variables bound with the *Var functions
+// of the flags hold the values of the request. Each request has its own input
+// and output buffers, so commands must not call os.Exit or write to the
+// process' standard streams directly. The context of a command (see
+// cobra.Command.Context) is that of the request, which is canceled when the
+// client goes away.
+package cobrahttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// AnnotationExpose is the annotation that exposes a command and its
+// descendants ("true") or hides them ("false") from a Handler.
+const AnnotationExpose = "cobra_annotation_http_expose"
+
+// maxRequestSize limits the size of the body of an execute request.
+const maxRequestSize = 1 << 20
+
+// Error types reported in ErrorInfo.Type.
+const (
+	// ErrorInvalidRequest reports a request that could not be decoded.
+	ErrorInvalidRequest = "invalid_request"
+	// ErrorNotExposed reports a command line that resolves to a command
+	// that is not exposed.
+	ErrorNotExposed = "not_exposed"
+	// ErrorCommand reports an error returned by executing the command line.
+	ErrorCommand = "command"
+)
+
+// Request is the body of an execute request.
+type Request struct {
+	// Args are the command line arguments, without the program name.
+	Args []string `json:"args"`
+	// Stdin is the input of the command.
+	Stdin string `json:"stdin,omitempty"`
+}
+
+// Response is the body of the response to an execute request.
+type Response struct {
+	// Command is the path of the command the arguments resolved to.
+	Command  string     `json:"command,omitempty"`
+	Stdout   string     `json:"stdout"`
+	Stderr   string     `json:"stderr"`
+	ExitCode int        `json:"exitCode"`
+	Error    *ErrorInfo `json:"error,omitempty"`
+}
+
+// ErrorInfo describes why a request failed.
+type ErrorInfo struct {
+	// Type is one of the Error* constants.
+	Type string `json:"type"`
+	// Message is the text of the error.
+	Message string `json:"message"`
+}
+
+// Handler is an http.Handler that serves a command tree.
+type Handler struct {
+	// ExposeByDefault exposes commands for which neither they nor any of
+	// their ancestors have an AnnotationExpose annotation.
+	ExposeByDefault bool
+
+	root *cobra.Command
+	// mu serializes the requests, which share the command tree.
+	mu sync.Mutex
+}
+
+// NewHandler returns a Handler for the command tree of root.
+// By default, only the commands opted in with AnnotationExpose are exposed.
+func NewHandler(root *cobra.Command) *Handler {
+	return &Handler{root: root.Root()}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimSuffix(r.URL.Path, "/") {
+	case "/execute":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.serveExecute(w, r)
+	case "/schema":
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.mu.Lock()
+		schema := h.schema(h.root)
+		h.mu.Unlock()
+		writeJSON(w, http.StatusOK, schema)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *Handler) serveExecute(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &Response{
+			ExitCode: 1,
+			Error:    &ErrorInfo{Type: ErrorInvalidRequest, Message: err.Error()},
+		})
+		return
+	}
+	if req.Args == nil {
+		// A nil slice would make cobra fall back to os.Args.
+		req.Args = []string{}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	root := h.root
+	root.InitDefaultHelpCmd()
+
+	target := h.resolve(req.Args)
+	if forwarded, err := target.ForwardedCommand(); err == nil && h.exposed(target) {
+		// A deprecated command that forwards runs its replacement, which
+		// has to be exposed as well.
+		target = forwarded
+	}
+	if !h.exposed(target) {
+		writeJSON(w, http.StatusForbidden, &Response{
+			Command:  target.CommandPath(),
+			ExitCode: 1,
+			Error: &ErrorInfo{
+				Type:    ErrorNotExposed,
+				Message: fmt.Sprintf("command %q is not exposed", target.CommandPath()),
+			},
+		})
+		return
+	}
+	root.ResetFlagsState()
+
+	oldIn, oldOut, oldErr := root.IOStreams()
+	defer func() {
+		root.SetIn(oldIn)
+		root.SetOut(oldOut)
+		root.SetErr(oldErr)
+	}()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+	root.SetIn(strings.NewReader(req.Stdin))
+	root.SetArgs(req.Args)
+
+	cmd, err := root.ExecuteContextC(r.Context())
+
+	resp := &Response{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		ExitCode: cobra.ExitCode(err),
+	}
+	if cmd != nil {
+		resp.Command = cmd.CommandPath()
+	}
+	if err != nil {
+		resp.Error = &ErrorInfo{Type: ErrorCommand, Message: err.Error()}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// resolve returns the command args resolve to. For the hidden completion
+// commands, it is the command of the line being completed, so that its
+// subcommands and flags are only listed if it is exposed.
+func (h *Handler) resolve(args []string) *cobra.Command {
+	if len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd) {
+		args = args[1:]
+	}
+
+	var target *cobra.Command
+	if h.root.TraverseChildren {
+		target, _, _ = h.root.Traverse(args)
+	} else {
+		target, _, _ = h.root.Find(args)
+	}
+	if target == nil {
+		return h.root
+	}
+	return target
+}
+
+// exposed determines if cmd may be executed through h.
+func (h *Handler) exposed(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if v, ok := c.Annotations[AnnotationExpose]; ok {
+			return v == "true"
+		}
+	}
+	return h.ExposeByDefault
+}
+
+// CommandSchema describes a command in the response of a schema request.
+type CommandSchema struct {
+	Name     string          `json:"name"`
+	Path     string          `json:"path"`
+	Use      string          `json:"use"`
+	Short    string          `json:"short,omitempty"`
+	Long     string          `json:"long,omitempty"`
+	Example  string          `json:"example,omitempty"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Runnable bool            `json:"runnable"`
+	Exposed  bool            `json:"exposed"`
+	Flags    []FlagSchema    `json:"flags,omitempty"`
+	Commands []CommandSchema `json:"commands,omitempty"`
+}
+
+// FlagSchema describes a flag in the response of a schema request.
+type FlagSchema struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default"`
+	Usage      string `json:"usage,omitempty"`
+	Persistent bool   `json:"persistent,omitempty"`
+}
+
+// schema describes cmd and all its available descendants that are exposed
+// themselves or have exposed descendants.
+func (h *Handler) schema(cmd *cobra.Command) CommandSchema {
+	cmd.InitDefaultHelpFlag()
+
+	s := CommandSchema{
+		Name:     cmd.Name(),
+		Path:     cmd.CommandPath(),
+		Use:      cmd.UseLine(),
+		Short:    cmd.Short,
+		Long:     cmd.Long,
+		Example:  cmd.Example,
+		Aliases:  cmd.Aliases,
+		Runnable: cmd.Runnable(),
+		Exposed:  h.exposed(cmd),
+	}
+
+	persistent := cmd.PersistentFlags()
+	addFlags := func(flags *pflag.FlagSet) {
+		flags.VisitAll(func(f *pflag.Flag) {
+			if f.Hidden || len(f.Deprecated) > 0 {
+				return
+			}
+			s.Flags = append(s.Flags, FlagSchema{
+				Name:       f.Name,
+				Shorthand:  f.Shorthand,
+				Type:       f.Value.Type(),
+				Default:    f.DefValue,
+				Usage:      f.Usage,
+				Persistent: persistent.Lookup(f.Name) != nil || cmd.InheritedFlags().Lookup(f.Name) != nil,
+			})
+		})
+	}
+	addFlags(cmd.LocalFlags())
+	addFlags(cmd.InheritedFlags())
+
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() {
+			continue
+		}
+		subSchema := h.schema(sub)
+		if subSchema.Exposed || len(subSchema.Commands) > 0 {
+			s.Commands = append(s.Commands, subSchema)
+		}
+	}
+	return s
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
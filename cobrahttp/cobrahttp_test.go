@@ -0,0 +1,363 @@
+package cobrahttp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func execute(t *testing.T, server *httptest.Server, body string) (int, *Response) {
+	resp, err := http.Post(server.URL+"/execute", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var r Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, &r
+}
+
+func TestExecute(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:         "greet",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		RunE: func(c *cobra.Command, args []string) error {
+			name, _ := c.Flags().GetString("name")
+			if name == "" {
+				return errors.New("no name")
+			}
+			fmt.Fprintln(c.OutOrStdout(), "hello", name)
+			return nil
+		},
+	})
+	rootCmd.PersistentFlags().String("name", "world", "")
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	status, r := execute(t, server, `{"args": ["greet", "--name", "cobra"]}`)
+	if status != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, status)
+	}
+	if r.Stdout != "hello cobra\n" || r.ExitCode != 0 || r.Error != nil || r.Command != "root greet" {
+		t.Errorf("Unexpected response: %+v", r)
+	}
+}
+
+func TestExecuteStdin(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:         "cat",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		RunE: func(c *cobra.Command, args []string) error {
+			b, err := ioutil.ReadAll(c.InOrStdin())
+			fmt.Fprint(c.ErrOrStderr(), string(b))
+			return err
+		},
+	})
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	_, r := execute(t, server, `{"args": ["cat"], "stdin": "some input"}`)
+	if r.Stderr != "some input" {
+		t.Errorf("Expected stderr %q, got %q", "some input", r.Stderr)
+	}
+}
+
+func TestExecuteCommandError(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:         "greet",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		RunE: func(c *cobra.Command, args []string) error {
+			name, _ := c.Flags().GetString("name")
+			if name == "" {
+				return errors.New("no name")
+			}
+			fmt.Fprintln(c.OutOrStdout(), "hello", name)
+			return nil
+		},
+	})
+	rootCmd.PersistentFlags().String("name", "world", "")
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	status, r := execute(t, server, `{"args": ["greet", "--name="]}`)
+	if status != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, status)
+	}
+	if r.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", r.ExitCode)
+	}
+	if r.Error == nil || r.Error.Type != ErrorCommand || r.Error.Message != "no name" {
+		t.Errorf("Unexpected error: %+v", r.Error)
+	}
+	if !strings.HasPrefix(r.Stderr, "Error: no name\n") {
+		t.Errorf("Unexpected stderr: %q", r.Stderr)
+	}
+}
+
+func TestExecuteNotExposed(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	adminCmd := &cobra.Command{Use: "admin", Annotations: map[string]string{AnnotationExpose: "true"}}
+	adminCmd.AddCommand(
+		&cobra.Command{Use: "drop", Annotations: map[string]string{AnnotationExpose: "false"}, Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "status", Run: func(*cobra.Command, []string) {}},
+	)
+	rootCmd.AddCommand(adminCmd, &cobra.Command{Use: "private", Run: func(*cobra.Command, []string) {}})
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	for _, args := range []string{`["private"]`, `["admin", "drop"]`, `[]`} {
+		status, r := execute(t, server, `{"args": `+args+`}`)
+		if status != http.StatusForbidden {
+			t.Errorf("%s: expected status %d, got %d", args, http.StatusForbidden, status)
+		}
+		if r.Error == nil || r.Error.Type != ErrorNotExposed {
+			t.Errorf("%s: unexpected error: %+v", args, r.Error)
+		}
+	}
+
+	// Exposure is inherited from the nearest annotated ancestor.
+	if status, r := execute(t, server, `{"args": ["admin", "status"]}`); status != http.StatusOK || r.Error != nil {
+		t.Errorf("Expected admin status to be exposed, got %d %+v", status, r.Error)
+	}
+}
+
+func TestExecuteForwardNotExposed(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	adminCmd := &cobra.Command{Use: "admin"}
+	adminCmd.AddCommand(&cobra.Command{Use: "drop", Run: func(*cobra.Command, []string) {}})
+	rootCmd.AddCommand(adminCmd, &cobra.Command{
+		Use:                   "old",
+		Deprecated:            "it was renamed",
+		DeprecatedReplacement: "admin drop",
+		DeprecatedForward:     true,
+		Annotations:           map[string]string{AnnotationExpose: "true"},
+		Run:                   func(*cobra.Command, []string) {},
+	})
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	status, r := execute(t, server, `{"args": ["old"]}`)
+	if status != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, status)
+	}
+	if r.Command != "root admin drop" {
+		t.Errorf("Expected the forward target to be reported, got %q", r.Command)
+	}
+}
+
+func TestExposeByDefault(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	adminCmd := &cobra.Command{Use: "admin"}
+	adminCmd.AddCommand(&cobra.Command{Use: "drop", Annotations: map[string]string{AnnotationExpose: "false"}, Run: func(*cobra.Command, []string) {}})
+	rootCmd.AddCommand(adminCmd, &cobra.Command{Use: "private", Run: func(*cobra.Command, []string) {}})
+	h := NewHandler(rootCmd)
+	h.ExposeByDefault = true
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	if status, _ := execute(t, server, `{"args": ["private"]}`); status != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, status)
+	}
+	if status, _ := execute(t, server, `{"args": ["admin", "drop"]}`); status != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d", http.StatusForbidden, status)
+	}
+}
+
+func TestExecuteInvalidRequest(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root", Run: func(*cobra.Command, []string) {}}
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	status, r := execute(t, server, `{"args": "greet"}`)
+	if status != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, status)
+	}
+	if r.Error == nil || r.Error.Type != ErrorInvalidRequest {
+		t.Errorf("Unexpected error: %+v", r.Error)
+	}
+
+	resp, err := http.Get(server.URL + "/execute")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status %d, got %d", http.StatusMethodNotAllowed, resp.StatusCode)
+	}
+}
+
+func TestConcurrentExecute(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	rootCmd.AddCommand(&cobra.Command{
+		Use:         "greet",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		RunE: func(c *cobra.Command, args []string) error {
+			name, _ := c.Flags().GetString("name")
+			if name == "" {
+				return errors.New("no name")
+			}
+			fmt.Fprintln(c.OutOrStdout(), "hello", name)
+			return nil
+		},
+	})
+	rootCmd.PersistentFlags().String("name", "world", "")
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, r := execute(t, server, fmt.Sprintf(`{"args": ["greet", "--name", "%d"]}`, i))
+			if expected := fmt.Sprintf("hello %d\n", i); r.Stdout != expected {
+				t.Errorf("Expected stdout %q, got %q", expected, r.Stdout)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestExecuteBoundFlags(t *testing.T) {
+	var name string
+	rootCmd := &cobra.Command{
+		Use:         "root",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		Run: func(c *cobra.Command, args []string) {
+			fmt.Fprintln(c.OutOrStdout(), "hello", name)
+		},
+	}
+	rootCmd.Flags().StringVar(&name, "name", "world", "")
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	for _, tc := range []struct{ args, expected string }{
+		{`["--name", "cobra"]`, "hello cobra\n"},
+		{`[]`, "hello world\n"},
+	} {
+		if _, r := execute(t, server, `{"args": `+tc.args+`}`); r.Stdout != tc.expected {
+			t.Errorf("%s: expected stdout %q, got %q", tc.args, tc.expected, r.Stdout)
+		}
+	}
+}
+
+func TestCompleteNotExposed(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	adminCmd := &cobra.Command{Use: "admin", Annotations: map[string]string{AnnotationExpose: "true"}}
+	adminCmd.AddCommand(
+		&cobra.Command{Use: "drop", Annotations: map[string]string{AnnotationExpose: "false"}, Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "status", Run: func(*cobra.Command, []string) {}},
+	)
+	rootCmd.AddCommand(adminCmd, &cobra.Command{Use: "private", Run: func(*cobra.Command, []string) {}})
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	for _, args := range []string{`["__complete", "private", ""]`, `["__completeNoDesc", "admin", "drop", "--"]`} {
+		if status, _ := execute(t, server, `{"args": `+args+`}`); status != http.StatusForbidden {
+			t.Errorf("%s: expected status %d, got %d", args, http.StatusForbidden, status)
+		}
+	}
+
+	status, r := execute(t, server, `{"args": ["__completeNoDesc", "admin", ""]}`)
+	if status != http.StatusOK || !strings.Contains(r.Stdout, "status\n") {
+		t.Errorf("Expected the subcommands of admin, got %d %q", status, r.Stdout)
+	}
+}
+
+func TestExecuteCanceled(t *testing.T) {
+	done := make(chan error, 1)
+	rootCmd := &cobra.Command{
+		Use:         "root",
+		Annotations: map[string]string{AnnotationExpose: "true"},
+		Run: func(c *cobra.Command, args []string) {
+			select {
+			case <-c.Context().Done():
+				done <- c.Context().Err()
+			case <-time.After(5 * time.Second):
+				done <- nil
+			}
+		},
+	}
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	client := &http.Client{Timeout: 50 * time.Millisecond}
+	if resp, err := client.Post(server.URL+"/execute", "application/json", strings.NewReader(`{"args": []}`)); err == nil {
+		resp.Body.Close()
+		t.Fatal("Expected the request to time out")
+	}
+	if err := <-done; err == nil {
+		t.Error("Expected the context of the command to be canceled")
+	}
+}
+
+func TestSchema(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "root"}
+	rootCmd.PersistentFlags().String("name", "world", "who to greet")
+	adminCmd := &cobra.Command{Use: "admin", Annotations: map[string]string{AnnotationExpose: "true"}}
+	adminCmd.AddCommand(
+		&cobra.Command{Use: "drop", Annotations: map[string]string{AnnotationExpose: "false"}, Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "status", Run: func(*cobra.Command, []string) {}},
+	)
+	rootCmd.AddCommand(
+		adminCmd,
+		&cobra.Command{Use: "cat", Annotations: map[string]string{AnnotationExpose: "true"}, Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "greet", Annotations: map[string]string{AnnotationExpose: "true"}, Run: func(*cobra.Command, []string) {}},
+		&cobra.Command{Use: "private", Run: func(*cobra.Command, []string) {}},
+	)
+	server := httptest.NewServer(NewHandler(rootCmd))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/schema")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var s CommandSchema
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Path != "root" || s.Exposed {
+		t.Errorf("Unexpected root schema: %+v", s)
+	}
+	var names []string
+	for _, c := range s.Commands {
+		names = append(names, c.Name)
+	}
+	if strings.Join(names, ",") != "admin,cat,greet" {
+		t.Errorf("Expected exposed commands admin,cat,greet, got %v", names)
+	}
+
+	admin := s.Commands[0]
+	if len(admin.Commands) != 1 || admin.Commands[0].Path != "root admin status" {
+		t.Errorf("Unexpected admin subcommands: %+v", admin.Commands)
+	}
+
+	greet := s.Commands[2]
+	var nameFlag *FlagSchema
+	for i := range greet.Flags {
+		if greet.Flags[i].Name == "name" {
+			nameFlag = &greet.Flags[i]
+		}
+	}
+	if nameFlag == nil || nameFlag.Type != "string" || nameFlag.Default != "world" || !nameFlag.Persistent {
+		t.Errorf("Unexpected name flag schema: %+v", nameFlag)
+	}
+}
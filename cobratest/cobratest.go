@@ -0,0 +1,171 @@
+// Package cobratest provides a harness for testing cobra applications.
+//
+// It executes a command tree with a given set of arguments, environment and
+// standard input, and captures everything a test usually wants to assert on:
+// standard output, standard error, the returned error, the command that was
+// resolved and the exit code the application would terminate with.
+package cobratest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// UpdateEnv is the environment variable that makes AssertGolden write the
+// golden files instead of comparing with them when it is set to a non-empty
+// value.
+const UpdateEnv = "COBRATEST_UPDATE"
+
+// Update makes AssertGolden write the golden files instead of comparing with
+// them. It is set if UpdateEnv is; tests with an -update flag of their own
+// can set it from TestMain.
+var Update = len(os.Getenv(UpdateEnv)) > 0
+
+// Options configures a single execution of a command tree.
+type Options struct {
+	// Args are the command line arguments, without the program name.
+	Args []string
+
+	// Env contains environment variables that are set for the duration of
+	// the execution. Variables that did not exist before are unset afterwards,
+	// existing ones are restored to their previous value.
+	Env map[string]string
+
+	// Stdin is the input of the command. If nil, the command reads from an
+	// empty input instead of the process' standard input.
+	Stdin io.Reader
+}
+
+// Result holds the observable outcome of executing a command tree.
+type Result struct {
+	// Stdout is everything the commands wrote to their output stream.
+	Stdout string
+	// Stderr is everything the commands wrote to their error stream.
+	Stderr string
+	// Err is the error returned by ExecuteC.
+	Err error
+	// Command is the command that was resolved from the arguments.
+	Command *cobra.Command
+	// ExitCode is the exit code corresponding to Err, see cobra.ExitCode.
+	ExitCode int
+}
+
+// Execute runs root with args and returns the result.
+func Execute(root *cobra.Command, args ...string) *Result {
+	return ExecuteWithOptions(root, Options{Args: args})
+}
+
+// ExecuteWithOptions runs root as configured by opts and returns the result.
+// The state left behind by previous executions, such as flag values, is
+// reset before the execution, so the same tree can be reused across
+// table-driven tests. The streams of root are restored once it returns.
+// As the environment is process-wide, executions that set Env must not
+// run in parallel.
+func ExecuteWithOptions(root *cobra.Command, opts Options) *Result {
+	restoreEnv := setEnv(opts.Env)
+	defer restoreEnv()
+
+	root.ResetFlagsState()
+
+	args := opts.Args
+	if args == nil {
+		// A nil slice would make cobra fall back to os.Args.
+		args = []string{}
+	}
+	stdin := opts.Stdin
+	if stdin == nil {
+		stdin = new(bytes.Buffer)
+	}
+
+	oldIn, oldOut, oldErr := root.IOStreams()
+	defer func() {
+		root.SetIn(oldIn)
+		root.SetOut(oldOut)
+		root.SetErr(oldErr)
+	}()
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	root.SetOut(stdout)
+	root.SetErr(stderr)
+	root.SetIn(stdin)
+	root.SetArgs(args)
+
+	c, err := root.ExecuteC()
+
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      err,
+		Command:  c,
+		ExitCode: cobra.ExitCode(err),
+	}
+}
+
+// setEnv sets the variables of env and returns a function that restores
+// the previous environment.
+func setEnv(env map[string]string) func() {
+	type previous struct {
+		value string
+		ok    bool
+	}
+	saved := make(map[string]previous, len(env))
+	for k, v := range env {
+		old, ok := os.LookupEnv(k)
+		saved[k] = previous{old, ok}
+		os.Setenv(k, v)
+	}
+	return func() {
+		for k, p := range saved {
+			if p.ok {
+				os.Setenv(k, p.value)
+			} else {
+				os.Unsetenv(k)
+			}
+		}
+	}
+}
+
+// AssertGolden compares got with the content of the golden file at path and
+// fails t if they differ. If Update is set, the golden file is written with
+// got instead.
+func AssertGolden(t testing.TB, path, got string) {
+	t.Helper()
+
+	if Update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with %s=1 to create it)", err, UpdateEnv)
+	}
+	if err := compareGolden(path, ensureLF(expected), ensureLF([]byte(got))); err != nil {
+		t.Error(err)
+	}
+}
+
+func compareGolden(path string, expected, got []byte) error {
+	if bytes.Equal(expected, got) {
+		return nil
+	}
+	return fmt.Errorf("output differs from %q (run with %s=1 to accept it)\nExpected:\n%s\nGot:\n%s", path, UpdateEnv, expected, got)
+}
+
+// ensureLF converts any \r\n to \n
+func ensureLF(content []byte) []byte {
+	return bytes.Replace(content, []byte("\r\n"), []byte("\n"), -1)
+}
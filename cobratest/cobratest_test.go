@@ -0,0 +1,178 @@
+package cobratest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type exitError struct{ code int }
+
+func (e exitError) Error() string { return fmt.Sprintf("exit %d", e.code) }
+func (e exitError) ExitCode() int { return e.code }
+
+func newTestRoot() *cobra.Command {
+	rootCmd := &cobra.Command{Use: "root", Short: "Root short description", Run: func(*cobra.Command, []string) {}}
+	rootCmd.PersistentFlags().String("name", "world", "who to greet")
+
+	greetCmd := &cobra.Command{
+		Use:   "greet",
+		Short: "Greet someone",
+		Run: func(c *cobra.Command, args []string) {
+			name, _ := c.Flags().GetString("name")
+			loud, _ := c.Flags().GetBool("loud")
+			greeting := "hello " + name
+			if loud {
+				greeting = strings.ToUpper(greeting)
+			}
+			fmt.Fprintln(c.OutOrStdout(), greeting)
+			fmt.Fprintln(c.ErrOrStderr(), "greeted")
+		},
+	}
+	greetCmd.Flags().Bool("loud", false, "shout the greeting")
+
+	envCmd := &cobra.Command{
+		Use: "env",
+		Run: func(c *cobra.Command, args []string) {
+			fmt.Fprint(c.OutOrStdout(), os.Getenv("COBRATEST_VALUE"))
+		},
+	}
+
+	catCmd := &cobra.Command{
+		Use: "cat",
+		RunE: func(c *cobra.Command, args []string) error {
+			b, err := ioutil.ReadAll(c.InOrStdin())
+			if err != nil {
+				return err
+			}
+			_, err = c.OutOrStdout().Write(b)
+			return err
+		},
+	}
+
+	failCmd := &cobra.Command{
+		Use:          "fail",
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				return exitError{3}
+			}
+			return errors.New("failed")
+		},
+	}
+
+	rootCmd.AddCommand(greetCmd, envCmd, catCmd, failCmd)
+	return rootCmd
+}
+
+func TestExecuteCapturesStreams(t *testing.T) {
+	r := Execute(newTestRoot(), "greet", "--name", "cobra")
+
+	if r.Err != nil {
+		t.Fatalf("Unexpected error: %v", r.Err)
+	}
+	if r.Stdout != "hello cobra\n" {
+		t.Errorf("Expected stdout %q, got %q", "hello cobra\n", r.Stdout)
+	}
+	if r.Stderr != "greeted\n" {
+		t.Errorf("Expected stderr %q, got %q", "greeted\n", r.Stderr)
+	}
+	if r.Command.Name() != "greet" {
+		t.Errorf("Expected resolved command %q, got %q", "greet", r.Command.Name())
+	}
+	if r.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d", r.ExitCode)
+	}
+}
+
+func TestExecuteError(t *testing.T) {
+	r := Execute(newTestRoot(), "fail")
+
+	if r.Err == nil || r.Err.Error() != "failed" {
+		t.Fatalf("Expected error %q, got %v", "failed", r.Err)
+	}
+	if r.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", r.ExitCode)
+	}
+	if r.Stderr != "Error: failed\n" {
+		t.Errorf("Expected stderr %q, got %q", "Error: failed\n", r.Stderr)
+	}
+	if r.Stdout != "" {
+		t.Errorf("Unexpected stdout: %q", r.Stdout)
+	}
+}
+
+func TestExecuteExitCoder(t *testing.T) {
+	r := Execute(newTestRoot(), "fail", "arg")
+	if r.ExitCode != 3 {
+		t.Errorf("Expected exit code 3, got %d", r.ExitCode)
+	}
+}
+
+func TestExecuteWithEnv(t *testing.T) {
+	os.Unsetenv("COBRATEST_VALUE")
+
+	r := ExecuteWithOptions(newTestRoot(), Options{
+		Args: []string{"env"},
+		Env:  map[string]string{"COBRATEST_VALUE": "set"},
+	})
+	if r.Stdout != "set" {
+		t.Errorf("Expected stdout %q, got %q", "set", r.Stdout)
+	}
+	if _, ok := os.LookupEnv("COBRATEST_VALUE"); ok {
+		t.Error("Expected COBRATEST_VALUE to be unset after the execution")
+	}
+}
+
+func TestExecuteWithStdin(t *testing.T) {
+	r := ExecuteWithOptions(newTestRoot(), Options{
+		Args:  []string{"cat"},
+		Stdin: strings.NewReader("some input"),
+	})
+	if r.Stdout != "some input" {
+		t.Errorf("Expected stdout %q, got %q", "some input", r.Stdout)
+	}
+}
+
+func TestExecuteResetsFlags(t *testing.T) {
+	rootCmd := newTestRoot()
+
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"greet", "--name", "cobra", "--loud"}, "HELLO COBRA\n"},
+		{[]string{"greet"}, "hello world\n"},
+		{[]string{"greet", "--loud"}, "HELLO WORLD\n"},
+	}
+	for _, tc := range tests {
+		r := Execute(rootCmd, tc.args...)
+		if r.Stdout != tc.expected {
+			t.Errorf("%v: expected stdout %q, got %q", tc.args, tc.expected, r.Stdout)
+		}
+	}
+}
+
+func TestExecuteRestoresStreams(t *testing.T) {
+	rootCmd := newTestRoot()
+	out := new(bytes.Buffer)
+	rootCmd.SetOut(out)
+
+	Execute(rootCmd, "greet")
+
+	in, gotOut, gotErr := rootCmd.IOStreams()
+	if in != nil || gotOut != out || gotErr != nil {
+		t.Errorf("Expected the streams of the root to be restored, got %v, %v, %v", in, gotOut, gotErr)
+	}
+}
+
+func TestAssertGolden(t *testing.T) {
+	r := Execute(newTestRoot(), "--help")
+	AssertGolden(t, "testdata/help.golden", r.Stdout)
+}
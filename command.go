@@ -17,12 +17,14 @@ package cobra
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	flag "github.com/spf13/pflag"
 )
@@ -54,7 +56,9 @@ type Command struct {
 	// Example is examples of how to use the command.
 	Example string
 
-	// ValidArgs is list of all valid non-flag arguments that are accepted in bash completions
+	// ValidArgs is list of all valid non-flag arguments that are accepted in bash completions.
+	// An argument may be followed by a tab and a description, e.g. "pod\tA group of containers",
+	// which is shown by shells that support it.
 	ValidArgs []string
 
 	// Expected arguments
@@ -71,9 +75,34 @@ type Command struct {
 	// Deprecated defines, if this command is deprecated and should print this string when used.
 	Deprecated string
 
+	// DeprecatedReplacement is the path of the command that replaces this deprecated command,
+	// relative to the root command, e.g. "config set". It is mentioned in the deprecation warning.
+	DeprecatedReplacement string
+
+	// DeprecatedForward defines, if calling this deprecated command should run the command
+	// named by DeprecatedReplacement with the same arguments instead.
+	DeprecatedForward bool
+
+	// DeprecatedRemovalVersion is the version in which this deprecated command will be removed.
+	DeprecatedRemovalVersion string
+
+	// DeprecatedAliases maps the deprecated entries of Aliases to the string that
+	// should be printed when they are used.
+	DeprecatedAliases map[string]string
+
+	// DeprecatedValidArgs maps the deprecated entries of ValidArgs to the string that
+	// should be printed when they are used. They are still accepted, but no longer
+	// offered in completions.
+	DeprecatedValidArgs map[string]string
+
 	// Hidden defines, if this command is hidden and should NOT show up in the list of available commands.
 	Hidden bool
 
+	// Stability defines how mature this command is. Alpha and beta commands are labelled
+	// in help and docs; experimental ones, and their subcommands, can only be used, and
+	// only show up in the list of available commands, once experimental features are enabled.
+	Stability Stability
+
 	// Annotations are key/value pairs that can be used by applications to identify or
 	// group commands.
 	Annotations map[string]string
@@ -153,6 +182,14 @@ type Command struct {
 	commandsMaxNameLen        int
 	// commandsAreSorted defines, if command slice are sorted or not.
 	commandsAreSorted bool
+	// shortcuts maps the names of shortcuts to the paths of their targets.
+	shortcuts map[string]string
+	// userAliasesFunc provides the aliases defined by the users of the program.
+	// It is only used on the root command.
+	userAliasesFunc func() (map[string]string, error)
+	// deprecationWarnings contains the deprecation warnings printed during the
+	// current execution. It is only used on the root command.
+	deprecationWarnings map[string]bool
 	// commandCalledAs is the name or alias value used to call this command.
 	commandCalledAs struct {
 		name   string
@@ -163,6 +200,8 @@ type Command struct {
 	args []string
 	// flagErrorBuf contains all error messages from pflag.
 	flagErrorBuf *bytes.Buffer
+	// flagSnapshots contains the state of flags before they were first parsed.
+	flagSnapshots map[*flag.Flag]flagSnapshot
 	// flags is full set of flags.
 	flags *flag.FlagSet
 	// pflags contains persistent flags.
@@ -177,8 +216,12 @@ type Command struct {
 	// that we can use on every pflag set and children commands
 	globNormFunc func(f *flag.FlagSet, name string) flag.NormalizedName
 
-	// output is an output writer defined by user.
-	output io.Writer
+	// inReader is a reader defined by the user that replaces stdin
+	inReader io.Reader
+	// outWriter is a writer defined by the user that replaces stdout
+	outWriter io.Writer
+	// errWriter is a writer defined by the user that replaces stderr
+	errWriter io.Writer
 	// usageFunc is usage func defined by user.
 	usageFunc func(*Command) error
 	// usageTemplate is usage template defined by user.
@@ -186,10 +229,37 @@ type Command struct {
 	// flagErrorFunc is func defined by user and it's called when the parsing of
 	// flags returns an error.
 	flagErrorFunc func(*Command, error) error
+	// flagValidators maps the names of flags to the validators added with
+	// AddFlagValidator.
+	flagValidators map[string][]FlagValidator
+	// flagGroups are the groups of flags marked with
+	// MarkFlagsMutuallyExclusive.
+	flagGroups [][]string
 	// helpTemplate is help template defined by user.
 	helpTemplate string
 	// helpFunc is help func defined by user.
 	helpFunc func(*Command, []string)
+	// theme is the theme set with SetTheme.
+	theme *Theme
+	// traceEnabled defines, if executions are traced. It is set with SetTrace.
+	traceEnabled bool
+	// ctx is the context of the current execution, set with ExecuteContext.
+	ctx context.Context
+	// signalHandling defines, if executions handle interrupts, and
+	// signalGrace how long they may take after one. They are set with
+	// HandleSignals.
+	signalHandling bool
+	signalGrace    time.Duration
+	// eventSink receives the usage events set with SetEventSink.
+	eventSink EventSink
+	// eventFlagValues defines, if events include the values of flags.
+	eventFlagValues bool
+	// errorType is the ErrorType of the error of the last execution.
+	errorType string
+	// helpPagerEnabled defines, if the output of the default help func is paged.
+	helpPagerEnabled bool
+	// helpPager is the pager set with SetHelpPager.
+	helpPager string
 	// helpCommand is command with usage 'help'. If it's not defined by user,
 	// cobra uses default help command.
 	helpCommand *Command
@@ -205,8 +275,34 @@ func (c *Command) SetArgs(a []string) {
 
 // SetOutput sets the destination for usage and error messages.
 // If output is nil, os.Stderr is used.
+// Deprecated: Use SetOut and/or SetErr instead
 func (c *Command) SetOutput(output io.Writer) {
-	c.output = output
+	c.outWriter = output
+	c.errWriter = output
+}
+
+// SetOut sets the destination for usage messages.
+// If newOut is nil, os.Stdout is used.
+func (c *Command) SetOut(newOut io.Writer) {
+	c.outWriter = newOut
+}
+
+// SetErr sets the destination for error messages.
+// If newErr is nil, os.Stderr is used.
+func (c *Command) SetErr(newErr io.Writer) {
+	c.errWriter = newErr
+}
+
+// SetIn sets the source for input data
+// If newIn is nil, os.Stdin is used.
+func (c *Command) SetIn(newIn io.Reader) {
+	c.inReader = newIn
+}
+
+// IOStreams returns the input, output and error streams set with SetIn,
+// SetOut and SetErr, or nil for the ones that are not set.
+func (c *Command) IOStreams() (in io.Reader, out, errOut io.Writer) {
+	return c.inReader, c.outWriter, c.errWriter
 }
 
 // SetUsageFunc sets usage function. Usage can be defined by application.
@@ -267,9 +363,19 @@ func (c *Command) OutOrStderr() io.Writer {
 	return c.getOut(os.Stderr)
 }
 
+// ErrOrStderr returns output to stderr
+func (c *Command) ErrOrStderr() io.Writer {
+	return c.getErr(os.Stderr)
+}
+
+// InOrStdin returns input to stdin
+func (c *Command) InOrStdin() io.Reader {
+	return c.getIn(os.Stdin)
+}
+
 func (c *Command) getOut(def io.Writer) io.Writer {
-	if c.output != nil {
-		return c.output
+	if c.outWriter != nil {
+		return c.outWriter
 	}
 	if c.HasParent() {
 		return c.parent.getOut(def)
@@ -277,6 +383,26 @@ func (c *Command) getOut(def io.Writer) io.Writer {
 	return def
 }
 
+func (c *Command) getErr(def io.Writer) io.Writer {
+	if c.errWriter != nil {
+		return c.errWriter
+	}
+	if c.HasParent() {
+		return c.parent.getErr(def)
+	}
+	return def
+}
+
+func (c *Command) getIn(def io.Reader) io.Reader {
+	if c.inReader != nil {
+		return c.inReader
+	}
+	if c.HasParent() {
+		return c.parent.getIn(def)
+	}
+	return def
+}
+
 // UsageFunc returns either the function set by SetUsageFunc for this command
 // or a parent, or it returns a default usage function.
 func (c *Command) UsageFunc() (f func(*Command) error) {
@@ -288,7 +414,7 @@ func (c *Command) UsageFunc() (f func(*Command) error) {
 	}
 	return func(c *Command) error {
 		c.mergePersistentFlags()
-		err := tmpl(c.OutOrStderr(), c.UsageTemplate(), c)
+		err := styledTmpl(c.OutOrStderr(), c.styledUsageTemplate(c.activeTheme(c.OutOrStderr())), c, c.activeTheme(c.OutOrStderr()))
 		if err != nil {
 			c.Println(err)
 		}
@@ -314,7 +440,13 @@ func (c *Command) HelpFunc() func(*Command, []string) {
 	}
 	return func(c *Command, a []string) {
 		c.mergePersistentFlags()
-		err := tmpl(c.OutOrStdout(), c.HelpTemplate(), c)
+		buf := new(bytes.Buffer)
+		err := styledTmpl(buf, c.HelpTemplate(), c, c.activeTheme(c.OutOrStdout()))
+		if err == nil {
+			err = c.writePaged(buf.Bytes())
+		} else {
+			c.Print(buf.String())
+		}
 		if err != nil {
 			c.Println(err)
 		}
@@ -331,14 +463,44 @@ func (c *Command) Help() error {
 
 // UsageString return usage string.
 func (c *Command) UsageString() string {
-	tmpOutput := c.output
+	if !c.hasUsageFunc() {
+		// Render the default usage directly, so it is styled according
+		// to the actual output.
+		c.mergePersistentFlags()
+		bb := new(bytes.Buffer)
+		if err := styledTmpl(bb, c.styledUsageTemplate(c.activeTheme(c.OutOrStdout())), c, c.activeTheme(c.OutOrStdout())); err != nil {
+			bb.WriteString(err.Error() + "\n")
+		}
+		return bb.String()
+	}
+
+	// Storing normal writers
+	tmpOutput := c.outWriter
+	tmpErr := c.errWriter
+
 	bb := new(bytes.Buffer)
-	c.SetOutput(bb)
+	c.outWriter = bb
+	c.errWriter = bb
+
 	c.Usage()
-	c.output = tmpOutput
+
+	// Setting things back to normal
+	c.outWriter = tmpOutput
+	c.errWriter = tmpErr
+
 	return bb.String()
 }
 
+// hasUsageFunc determines if c or a parent has a usage func defined by user.
+func (c *Command) hasUsageFunc() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.usageFunc != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // FlagErrorFunc returns either the function set by SetFlagErrorFunc for this
 // command or a parent, or it returns a function which returns the original
 // error.
@@ -385,16 +547,8 @@ func (c *Command) NamePadding() int {
 	return c.parent.commandsMaxNameLen
 }
 
-// UsageTemplate returns usage template for the command.
-func (c *Command) UsageTemplate() string {
-	if c.usageTemplate != "" {
-		return c.usageTemplate
-	}
-
-	if c.HasParent() {
-		return c.parent.UsageTemplate()
-	}
-	return `Usage:{{if .Runnable}}
+// defaultUsageTemplate is the usage template of commands that don't set one.
+const defaultUsageTemplate = `Usage:{{if .Runnable}}
   {{.UseLine}}{{end}}{{if .HasAvailableSubCommands}}
   {{.CommandPath}} [command]{{end}}{{if gt (len .Aliases) 0}}
 
@@ -405,19 +559,36 @@ Examples:
 {{.Example}}{{end}}{{if .HasAvailableSubCommands}}
 
 Available Commands:{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
-  {{rpad .Name .NamePadding }} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableLocalFlags}}
+  {{rpad .Name .NamePadding }} {{.Short}}{{with .StabilityLabel}} {{.}}{{end}}{{end}}{{end}}{{end}}{{if .HasAvailableShortcuts}}
+
+Shortcuts:
+{{.ShortcutsUsage | trimTrailingWhitespaces}}{{end}}{{if .HasUserAliases}}
+
+User Aliases:
+{{.UserAliasesUsage | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableLocalFlags}}
 
 Flags:
-{{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+{{(.UsageFlags .LocalFlags).FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
 
 Global Flags:
-{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
+{{(.UsageFlags .InheritedFlags).FlagUsages | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
 
 Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
   {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
 
 Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
 `
+
+// UsageTemplate returns usage template for the command.
+func (c *Command) UsageTemplate() string {
+	if c.usageTemplate != "" {
+		return c.usageTemplate
+	}
+
+	if c.HasParent() {
+		return c.parent.UsageTemplate()
+	}
+	return defaultUsageTemplate
 }
 
 // HelpTemplate return help template for the command.
@@ -429,7 +600,9 @@ func (c *Command) HelpTemplate() string {
 	if c.HasParent() {
 		return c.parent.HelpTemplate()
 	}
-	return `{{with (or .Long .Short)}}{{. | trimTrailingWhitespaces}}
+	return `{{if .Deprecated}}DEPRECATED: {{.DeprecationMessage}}
+
+{{end}}{{with (or .Long .Short)}}{{. | trimTrailingWhitespaces}}
 
 {{end}}{{if or .Runnable .HasSubCommands}}{{.UsageString}}{{end}}`
 }
@@ -471,38 +644,42 @@ func stripFlags(args []string, c *Command) []string {
 	if len(args) == 0 {
 		return args
 	}
-	c.mergePersistentFlags()
 
 	commands := []string{}
+	for _, i := range nonFlagArgs(args, c) {
+		commands = append(commands, args[i])
+	}
+	return commands
+}
+
+// nonFlagArgs returns the indexes of the args that stripFlags keeps: those
+// before "--" that are neither flags of c nor the values of its flags.
+func nonFlagArgs(args []string, c *Command) []int {
+	c.mergePersistentFlags()
+
+	indexes := []int{}
 	flags := c.Flags()
 
-Loop:
-	for len(args) > 0 {
-		s := args[0]
-		args = args[1:]
+	for i := 0; i < len(args); i++ {
+		s := args[i]
 		switch {
 		case s == "--":
 			// "--" terminates the flags
-			break Loop
+			return indexes
 		case strings.HasPrefix(s, "--") && !strings.Contains(s, "=") && !hasNoOptDefVal(s[2:], flags):
 			// If '--flag arg' then
-			// delete arg from args.
+			// skip arg.
 			fallthrough // (do the same as below)
 		case strings.HasPrefix(s, "-") && !strings.Contains(s, "=") && len(s) == 2 && !shortHasNoOptDefVal(s[1:], flags):
 			// If '-f arg' then
-			// delete 'arg' from args or break the loop if len(args) <= 1.
-			if len(args) <= 1 {
-				break Loop
-			} else {
-				args = args[1:]
-				continue
-			}
+			// skip arg.
+			i++
 		case s != "" && !strings.HasPrefix(s, "-"):
-			commands = append(commands, s)
+			indexes = append(indexes, i)
 		}
 	}
 
-	return commands
+	return indexes
 }
 
 // argsMinusFirstX removes only the first x from args.  Otherwise, commands that look like
@@ -527,6 +704,14 @@ func isFlagArg(arg string) bool {
 // Find the target command given the args and command tree
 // Meant to be run on the highest node. Only searches down.
 func (c *Command) Find(args []string) (*Command, []string, error) {
+	if !c.HasParent() {
+		expanded, err := c.expandUserAliases(args)
+		if err != nil {
+			return c, args, err
+		}
+		args = expanded
+	}
+
 	var innerfind func(*Command, []string) (*Command, []string)
 
 	innerfind = func(c *Command, innerArgs []string) (*Command, []string) {
@@ -579,6 +764,11 @@ func (c *Command) findNext(next string) *Command {
 		}
 	}
 
+	if cmd := c.shortcutTarget(next); cmd != nil {
+		cmd.commandCalledAs.name = next
+		return cmd
+	}
+
 	if len(matches) == 1 {
 		return matches[0]
 	}
@@ -589,6 +779,14 @@ func (c *Command) findNext(next string) *Command {
 // Traverse the command tree to find the command, and parse args for
 // each parent.
 func (c *Command) Traverse(args []string) (*Command, []string, error) {
+	if !c.HasParent() {
+		expanded, err := c.expandUserAliases(args)
+		if err != nil {
+			return c, args, err
+		}
+		args = expanded
+	}
+
 	flags := []string{}
 	inFlag := false
 
@@ -676,9 +874,20 @@ func (c *Command) execute(a []string) (err error) {
 	if c == nil {
 		return fmt.Errorf("Called Execute() on a nil Command")
 	}
+	errorType := ErrorTypeFlag
+	defer func() {
+		c.errorType = ""
+		if err != nil && err != flag.ErrHelp {
+			c.errorType = errorType
+			c.tracef("error in %q: %v", c.CommandPath(), err)
+		}
+	}()
 
 	if len(c.Deprecated) > 0 {
-		c.Printf("Command %q is deprecated, %s\n", c.Name(), c.Deprecated)
+		c.warnDeprecated(fmt.Sprintf("Command %q is deprecated, %s", c.Name(), c.DeprecationMessage()))
+	}
+	if msg, ok := c.DeprecatedAliases[c.CalledAs()]; ok {
+		c.warnDeprecated(fmt.Sprintf("Alias %q for command %q is deprecated, %s", c.CalledAs(), c.Name(), msg))
 	}
 
 	// initialize help and version flag at the last point possible to allow for user
@@ -690,6 +899,16 @@ func (c *Command) execute(a []string) (err error) {
 	if err != nil {
 		return c.FlagErrorFunc()(c, err)
 	}
+	if c.DisableFlagParsing {
+		c.traceResolution(a)
+	} else {
+		c.traceResolution(c.Flags().Args())
+	}
+
+	if err := c.checkExperimental(); err != nil {
+		errorType = ErrorTypeExperimental
+		return err
+	}
 
 	// If help is called, regardless of other flags, return we want help.
 	// Also say we need help if the command isn't runnable.
@@ -725,61 +944,78 @@ func (c *Command) execute(a []string) (err error) {
 		return flag.ErrHelp
 	}
 
-	c.preRun()
+	if err := c.validateFlags(); err != nil {
+		return c.FlagErrorFunc()(c, err)
+	}
+	if err := c.validateFlagGroups(); err != nil {
+		return c.FlagErrorFunc()(c, err)
+	}
 
 	argWoFlags := c.Flags().Args()
 	if c.DisableFlagParsing {
 		argWoFlags = a
 	}
+	errorType = ErrorTypeArgs
+	if c.explaining() {
+		return c.explain(c.OutOrStdout(), argWoFlags)
+	}
+
+	c.preRun()
+	defer c.postRun()
+
+	c.warnDeprecatedArgs(argWoFlags)
 
 	if err := c.ValidateArgs(argWoFlags); err != nil {
 		return err
 	}
 
+	errorType = ErrorTypeRun
+
 	for p := c; p != nil; p = p.Parent() {
 		if p.PersistentPreRunE != nil {
-			if err := p.PersistentPreRunE(c, argWoFlags); err != nil {
+			if err := c.runHook("PersistentPreRunE", p, func() error { return p.PersistentPreRunE(c, argWoFlags) }); err != nil {
 				return err
 			}
 			break
 		} else if p.PersistentPreRun != nil {
-			p.PersistentPreRun(c, argWoFlags)
+			c.runHook("PersistentPreRun", p, func() error { p.PersistentPreRun(c, argWoFlags); return nil })
 			break
 		}
 	}
 	if c.PreRunE != nil {
-		if err := c.PreRunE(c, argWoFlags); err != nil {
+		if err := c.runHook("PreRunE", c, func() error { return c.PreRunE(c, argWoFlags) }); err != nil {
 			return err
 		}
 	} else if c.PreRun != nil {
-		c.PreRun(c, argWoFlags)
+		c.runHook("PreRun", c, func() error { c.PreRun(c, argWoFlags); return nil })
 	}
 
 	if err := c.validateRequiredFlags(); err != nil {
+		errorType = ErrorTypeFlag
 		return err
 	}
 	if c.RunE != nil {
-		if err := c.RunE(c, argWoFlags); err != nil {
+		if err := c.runHook("RunE", c, func() error { return c.RunE(c, argWoFlags) }); err != nil {
 			return err
 		}
 	} else {
-		c.Run(c, argWoFlags)
+		c.runHook("Run", c, func() error { c.Run(c, argWoFlags); return nil })
 	}
 	if c.PostRunE != nil {
-		if err := c.PostRunE(c, argWoFlags); err != nil {
+		if err := c.runHook("PostRunE", c, func() error { return c.PostRunE(c, argWoFlags) }); err != nil {
 			return err
 		}
 	} else if c.PostRun != nil {
-		c.PostRun(c, argWoFlags)
+		c.runHook("PostRun", c, func() error { c.PostRun(c, argWoFlags); return nil })
 	}
 	for p := c; p != nil; p = p.Parent() {
 		if p.PersistentPostRunE != nil {
-			if err := p.PersistentPostRunE(c, argWoFlags); err != nil {
+			if err := c.runHook("PersistentPostRunE", p, func() error { return p.PersistentPostRunE(c, argWoFlags) }); err != nil {
 				return err
 			}
 			break
 		} else if p.PersistentPostRun != nil {
-			p.PersistentPostRun(c, argWoFlags)
+			c.runHook("PersistentPostRun", p, func() error { p.PersistentPostRun(c, argWoFlags); return nil })
 			break
 		}
 	}
@@ -788,9 +1024,45 @@ func (c *Command) execute(a []string) (err error) {
 }
 
 func (c *Command) preRun() {
+	start := time.Now()
 	for _, x := range initializers {
 		x()
 	}
+	if len(initializers) > 0 {
+		c.tracef("%d OnInitialize functions ran in %v", len(initializers), time.Since(start))
+	}
+}
+
+func (c *Command) postRun() {
+	for _, x := range finalizers {
+		x()
+	}
+}
+
+// Context returns the context of the current execution of c, which is the
+// context passed to ExecuteContext, cancelled on interrupts if HandleSignals
+// is enabled. It returns context.Background() outside executions and for
+// executions without a context.
+func (c *Command) Context() context.Context {
+	if c.ctx == nil {
+		return context.Background()
+	}
+	return c.ctx
+}
+
+// ExecuteContext is the same as Execute, but sets ctx as the context that
+// the executed command returns from Context.
+func (c *Command) ExecuteContext(ctx context.Context) error {
+	_, err := c.ExecuteContextC(ctx)
+	return err
+}
+
+// ExecuteContextC is the same as ExecuteC, but sets ctx as the context that
+// the executed command returns from Context.
+func (c *Command) ExecuteContextC(ctx context.Context) (*Command, error) {
+	c.Root().ctx = ctx
+	defer func() { c.Root().ctx = nil }()
+	return c.ExecuteC()
 }
 
 // Execute uses the args (os.Args[1:] by default)
@@ -817,6 +1089,30 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 	// overriding
 	c.InitDefaultHelpCmd()
 
+	c.deprecationWarnings = nil
+
+	if c.signalHandling {
+		defer c.handleSignals()()
+	}
+
+	start := time.Now()
+	executed := false
+	defer func() {
+		finished := Event{Type: EventCommandFinished, Duration: time.Since(start), ExitCode: ExitCode(err)}
+		if err != nil {
+			finished.ErrorType = ErrorTypeCommand
+			if executed {
+				finished.ErrorType = cmd.errorType
+			}
+		}
+		target := c
+		if cmd != nil {
+			target = cmd
+		}
+		finished.Command = target.CommandPath()
+		target.sendEvent(finished)
+	}()
+
 	args := c.args
 
 	// Workaround FAIL with "go test -v" or "cobra.test -test.v", see #155
@@ -824,6 +1120,8 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		args = os.Args[1:]
 	}
 
+	c.tracef("args: %q", args)
+	c.initCompleteCmd(args)
 	var flags []string
 	if c.TraverseChildren {
 		cmd, flags, err = c.Traverse(args)
@@ -831,13 +1129,14 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		cmd, flags, err = c.Find(args)
 	}
 	if err != nil {
+		c.tracef("resolving the command failed: %v", err)
 		// If found parse to a subcommand and then failed, talk about the subcommand
 		if cmd != nil {
 			c = cmd
 		}
 		if !c.SilenceErrors {
-			c.Println("Error:", err.Error())
-			c.Printf("Run '%v --help' for usage.\n", c.CommandPath())
+			c.PrintErrln(styleError(err.Error(), c.activeTheme(c.ErrOrStderr())))
+			c.PrintErrf("Run '%v --help' for usage.\n", c.CommandPath())
 		}
 		return c, err
 	}
@@ -847,6 +1146,26 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		cmd.commandCalledAs.name = cmd.Name()
 	}
 
+	if len(cmd.Deprecated) > 0 && cmd.DeprecatedForward {
+		target, err := cmd.ForwardedCommand()
+		if err != nil {
+			if !c.SilenceErrors {
+				c.PrintErrln(styleError(err.Error(), c.activeTheme(c.ErrOrStderr())))
+			}
+			return cmd, err
+		}
+		cmd.warnDeprecated(fmt.Sprintf("Command %q is deprecated, %s", cmd.Name(), cmd.DeprecationMessage()))
+		target.commandCalledAs.called = true
+		target.commandCalledAs.name = target.Name()
+		cmd.tracef("forwarding deprecated command %q to %q", cmd.CommandPath(), target.CommandPath())
+		cmd = target
+	}
+	cmd.tracef("resolved command %q, called as %q, with args %q", cmd.CommandPath(), cmd.CalledAs(), flags)
+	cmd.sendEvent(Event{Type: EventCommandStarted, Command: cmd.CommandPath()})
+
+	cmd.ctx = c.ctx
+	defer func() { cmd.ctx = nil }()
+	executed = true
 	err = cmd.execute(flags)
 	if err != nil {
 		// Always show help if requested, even if SilenceErrors is in
@@ -859,7 +1178,7 @@ func (c *Command) ExecuteC() (cmd *Command, err error) {
 		// If root command has SilentErrors flagged,
 		// all subcommands should respect it
 		if !cmd.SilenceErrors && !c.SilenceErrors {
-			c.Println("Error:", err.Error())
+			c.PrintErrln(styleError(err.Error(), c.activeTheme(c.ErrOrStderr())))
 		}
 
 		// If root command has SilentUsage flagged,
@@ -950,6 +1269,14 @@ func (c *Command) InitDefaultHelpCmd() {
 Simply type ` + c.Name() + ` help [path to command] for full details.`,
 
 			Run: func(c *Command, args []string) {
+				if term, _ := c.Flags().GetString("search"); len(term) > 0 {
+					printHelpSearch(c, term)
+					return
+				}
+				if len(args) == 1 && args[0] == "topics" && c.Root().findNext("topics") == nil {
+					printHelpTopics(c)
+					return
+				}
 				cmd, _, e := c.Root().Find(args)
 				if cmd == nil || e != nil {
 					c.Printf("Unknown help topic %#q\n", args)
@@ -960,6 +1287,14 @@ Simply type ` + c.Name() + ` help [path to command] for full details.`,
 				}
 			},
 		}
+		if len(c.HelpTopics()) > 0 {
+			c.helpCommand.Use = "help [command | topics]"
+			c.helpCommand.Long = `Help provides help for any command in the application.
+Simply type ` + c.Name() + ` help [path to command] for full details,
+` + c.Name() + ` help topics for a list of help topics, or
+` + c.Name() + ` help --search <term> to search all of them.`
+			c.helpCommand.Flags().String("search", "", "search the help of all commands and help topics")
+		}
 	}
 	c.RemoveCommand(c.helpCommand)
 	c.AddCommand(c.helpCommand)
@@ -1068,6 +1403,21 @@ func (c *Command) Printf(format string, i ...interface{}) {
 	c.Print(fmt.Sprintf(format, i...))
 }
 
+// PrintErr is a convenience method to Print to the defined Err output, fallback to Stderr if not set.
+func (c *Command) PrintErr(i ...interface{}) {
+	fmt.Fprint(c.ErrOrStderr(), i...)
+}
+
+// PrintErrln is a convenience method to Println to the defined Err output, fallback to Stderr if not set.
+func (c *Command) PrintErrln(i ...interface{}) {
+	c.PrintErr(fmt.Sprintln(i...))
+}
+
+// PrintErrf is a convenience method to Printf to the defined Err output, fallback to Stderr if not set.
+func (c *Command) PrintErrf(format string, i ...interface{}) {
+	c.PrintErr(fmt.Sprintf(format, i...))
+}
+
 // CommandPath returns the full path to this command.
 func (c *Command) CommandPath() string {
 	if c.HasParent() {
@@ -1179,9 +1529,17 @@ func (c *Command) hasNameOrAliasPrefix(prefix string) bool {
 	return false
 }
 
-// NameAndAliases returns a list of the command name and all aliases
+// NameAndAliases returns a list of the command name and all aliases.
+// Deprecated aliases are marked as such.
 func (c *Command) NameAndAliases() string {
-	return strings.Join(append([]string{c.Name()}, c.Aliases...), ", ")
+	names := []string{c.Name()}
+	for _, alias := range c.Aliases {
+		if c.IsDeprecatedAlias(alias) {
+			alias += " (deprecated)"
+		}
+		names = append(names, alias)
+	}
+	return strings.Join(names, ", ")
 }
 
 // HasExample determines if the command has example.
@@ -1202,7 +1560,7 @@ func (c *Command) HasSubCommands() bool {
 // IsAvailableCommand determines if a command is available as a non-help command
 // (this includes all non deprecated/hidden commands).
 func (c *Command) IsAvailableCommand() bool {
-	if len(c.Deprecated) != 0 || c.Hidden {
+	if len(c.Deprecated) != 0 || c.Hidden || !c.experimentalAllowed() {
 		return false
 	}
 
@@ -1420,16 +1778,16 @@ func (c *Command) HasAvailablePersistentFlags() bool {
 	return c.PersistentFlags().HasAvailableFlags()
 }
 
-// HasAvailableLocalFlags checks if the command has flags specifically declared locally which are not hidden
-// or deprecated.
+// HasAvailableLocalFlags checks if the command has flags specifically declared locally which are not hidden,
+// deprecated or experimental while experimental features are disabled.
 func (c *Command) HasAvailableLocalFlags() bool {
-	return c.LocalFlags().HasAvailableFlags()
+	return c.UsageFlags(c.LocalFlags()).HasAvailableFlags()
 }
 
 // HasAvailableInheritedFlags checks if the command has flags inherited from its parent command which are
-// not hidden or deprecated.
+// not hidden, deprecated or experimental while experimental features are disabled.
 func (c *Command) HasAvailableInheritedFlags() bool {
-	return c.InheritedFlags().HasAvailableFlags()
+	return c.UsageFlags(c.InheritedFlags()).HasAvailableFlags()
 }
 
 // Flag climbs up the command tree looking for matching flag.
@@ -1467,6 +1825,7 @@ func (c *Command) ParseFlags(args []string) error {
 	}
 	beforeErrorBufLen := c.flagErrorBuf.Len()
 	c.mergePersistentFlags()
+	c.snapshotFlags()
 
 	//do it here after merging all flags and just before parse
 	c.Flags().ParseErrorsWhitelist = flag.ParseErrorsWhitelist(c.FParseErrWhitelist)
@@ -1474,7 +1833,13 @@ func (c *Command) ParseFlags(args []string) error {
 	err := c.Flags().Parse(args)
 	// Print warnings if they occurred (e.g. deprecated flag messages).
 	if c.flagErrorBuf.Len()-beforeErrorBufLen > 0 && err == nil {
-		c.Print(c.flagErrorBuf.String())
+		warnings := c.flagErrorBuf.String()[beforeErrorBufLen:]
+		for _, warning := range strings.Split(strings.TrimSuffix(warnings, "\n"), "\n") {
+			c.warnDeprecated(warning)
+		}
+	}
+	if err == nil {
+		err = c.checkFlagEnums()
 	}
 
 	return err
@@ -2,6 +2,8 @@ package cobra
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
@@ -1381,6 +1383,55 @@ func TestSetOutput(t *testing.T) {
 	}
 }
 
+func TestSetOut(t *testing.T) {
+	c := &Command{}
+	c.SetOut(nil)
+	if out := c.OutOrStdout(); out != os.Stdout {
+		t.Errorf("Expected setting output to nil to revert back to stdout")
+	}
+}
+
+func TestSetErr(t *testing.T) {
+	c := &Command{}
+	c.SetErr(nil)
+	if out := c.ErrOrStderr(); out != os.Stderr {
+		t.Errorf("Expected setting error to nil to revert back to stderr")
+	}
+}
+
+func TestSetIn(t *testing.T) {
+	c := &Command{}
+	c.SetIn(nil)
+	if out := c.InOrStdin(); out != os.Stdin {
+		t.Errorf("Expected setting input to nil to revert back to stdin")
+	}
+}
+
+func TestErrorsGoToErr(t *testing.T) {
+	c := &Command{
+		Use:          "c",
+		SilenceUsage: true,
+		RunE: func(*Command, []string) error {
+			return fmt.Errorf("an error")
+		},
+	}
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	c.SetOut(out)
+	c.SetErr(errOut)
+	c.SetArgs([]string{})
+
+	if err := c.Execute(); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if out.Len() != 0 {
+		t.Errorf("Unexpected output: %q", out.String())
+	}
+	if errOut.String() != "Error: an error\n" {
+		t.Errorf("Expected error output %q, got %q", "Error: an error\n", errOut.String())
+	}
+}
+
 func TestFlagErrorFunc(t *testing.T) {
 	c := &Command{Use: "c", Run: emptyRun}
 
@@ -1733,3 +1784,46 @@ func TestFParseErrWhitelistSiblingCommand(t *testing.T) {
 	}
 	checkStringContains(t, output, "unknown flag: --unknown")
 }
+
+func TestExecuteContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	var got interface{}
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{Use: "child", Run: func(cmd *Command, args []string) {
+		got = cmd.Context().Value(key{})
+	}}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetArgs([]string{"child"})
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got != "value" {
+		t.Errorf("Expected the context of the execution, got value %v", got)
+	}
+	if childCmd.Context() != context.Background() {
+		t.Error("Expected a background context after the execution")
+	}
+}
+
+func TestOnFinalize(t *testing.T) {
+	var calls []string
+	OnFinalize(func() { calls = append(calls, "finalize") })
+	defer func() { finalizers = nil }()
+
+	rootCmd := &Command{
+		Use:     "root",
+		PreRun:  func(*Command, []string) { calls = append(calls, "prerun") },
+		RunE:    func(*Command, []string) error { return errors.New("failed") },
+		PostRun: func(*Command, []string) { calls = append(calls, "postrun") },
+	}
+	if _, err := executeCommand(rootCmd); err == nil {
+		t.Fatal("Expected an error")
+	}
+	expected := []string{"prerun", "finalize"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Errorf("Expected calls %q, got %q", expected, calls)
+	}
+}
@@ -0,0 +1,319 @@
+package cobra
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// ShellCompDirective is a bit map of hints for a shell on how to complete
+// the candidates returned by the hidden completion command.
+type ShellCompDirective int
+
+const (
+	// ShellCompDirectiveError indicates an error occurred and the
+	// candidates should be ignored.
+	ShellCompDirectiveError ShellCompDirective = 1 << iota
+	// ShellCompDirectiveNoSpace indicates that the shell should not add a
+	// space after the completion, even if there is a single candidate.
+	ShellCompDirectiveNoSpace
+	// ShellCompDirectiveNoFileComp indicates that the shell should not fall
+	// back to completing file names when there are no candidates.
+	ShellCompDirectiveNoFileComp
+	// ShellCompDirectiveFilterFileExt indicates that the candidates are
+	// file name extensions, and the shell should complete the file names
+	// with those extensions.
+	ShellCompDirectiveFilterFileExt
+	// ShellCompDirectiveFilterDirs indicates that the shell should only
+	// complete directory names, in the directory given as the only
+	// candidate if there is one.
+	ShellCompDirectiveFilterDirs
+
+	// ShellCompDirectiveDefault indicates that the shell should behave as
+	// usual: add a space after a single candidate and complete file names
+	// when there are none.
+	ShellCompDirectiveDefault ShellCompDirective = 0
+)
+
+const (
+	// ShellCompRequestCmd is the name of the hidden command that prints
+	// the completion candidates, with descriptions, for the words following
+	// it, the last of which is the word to complete. The last line of its
+	// output is a colon followed by the ShellCompDirective.
+	ShellCompRequestCmd = "__complete"
+	// ShellCompNoDescRequestCmd is the name of the hidden command that
+	// works like ShellCompRequestCmd, but prints no descriptions.
+	ShellCompNoDescRequestCmd = "__completeNoDesc"
+)
+
+// initCompleteCmd adds the hidden completion command to c if args request
+// it and c doesn't have it yet.
+func (c *Command) initCompleteCmd(args []string) {
+	if len(args) == 0 || (args[0] != ShellCompRequestCmd && args[0] != ShellCompNoDescRequestCmd) {
+		return
+	}
+	if cmd, _, err := c.Find(args[:1]); err == nil && cmd != c {
+		return
+	}
+
+	for _, name := range []string{ShellCompRequestCmd, ShellCompNoDescRequestCmd} {
+		descriptions := name == ShellCompRequestCmd
+		c.AddCommand(&Command{
+			Use:                   name + " [command-line]",
+			Short:                 "Request completion candidates for a command line",
+			Hidden:                true,
+			DisableFlagParsing:    true,
+			DisableFlagsInUseLine: true,
+			Run: func(cmd *Command, args []string) {
+				toComplete := ""
+				if len(args) > 0 {
+					toComplete = args[len(args)-1]
+					args = args[:len(args)-1]
+				}
+				candidates, directive := cmd.Root().completionCandidates(args, toComplete)
+				out := cmd.OutOrStdout()
+				for _, candidate := range candidates {
+					if !descriptions {
+						candidate = validArgValue(candidate)
+					}
+					fmt.Fprintln(out, candidate)
+				}
+				fmt.Fprintf(out, ":%d\n", directive)
+			},
+		})
+	}
+}
+
+// completions returns the candidates for completing toComplete, the word
+// that follows args on a command line of c, without their descriptions.
+func (c *Command) completions(args []string, toComplete string) []string {
+	candidates, _ := c.completionCandidates(args, toComplete)
+	for i, candidate := range candidates {
+		candidates[i] = validArgValue(candidate)
+	}
+	return candidates
+}
+
+// completionCandidates returns the candidates for completing toComplete,
+// the word that follows args on a command line of c, in the form
+// "value\tdescription", and how the shell should complete them. The
+// candidates are derived from the same information the completion script
+// generators use: available subcommands and their aliases, flags that are
+// neither hidden nor deprecated, the values of enum flags, and ValidArgs;
+// shortcuts and the user aliases of the root command are offered as well.
+// Deprecated aliases and ValidArgs are left out, as are experimental flags
+// unless experimental features are enabled.
+func (c *Command) completionCandidates(args []string, toComplete string) ([]string, ShellCompDirective) {
+	cmd, args, err := c.Find(args)
+	if err != nil {
+		return nil, ShellCompDirectiveError
+	}
+	cmd.InitDefaultHelpFlag()
+
+	var candidates []string
+	directive := ShellCompDirectiveNoFileComp
+	if f := flagExpectingValue(cmd, args); f != nil {
+		candidates, directive = flagValueCandidates(f, "")
+	} else if i := strings.Index(toComplete, "="); i > 0 && strings.HasPrefix(toComplete, "-") {
+		if f := lookupCompletionFlag(cmd, toComplete[:i]); f != nil {
+			candidates, directive = flagValueCandidates(f, toComplete[:i+1])
+		}
+	} else if strings.HasPrefix(toComplete, "-") {
+		// The flags are ordered already, with the required ones first.
+		return matchCandidates(completionFlags(cmd, args), toComplete), directive
+	} else if len(cmd.ValidArgs) > 0 {
+		for _, arg := range cmd.ValidArgs {
+			if !cmd.IsDeprecatedValidArg(validArgValue(arg)) {
+				candidates = append(candidates, arg)
+			}
+		}
+	} else if len(stripFlags(args, cmd)) == 0 && cmd.HasSubCommands() {
+		for _, sub := range cmd.Commands() {
+			if !sub.IsAvailableCommand() && sub != cmd.helpCommand {
+				continue
+			}
+			candidates = append(candidates, completionCandidate(sub.Name(), sub.Short))
+			for _, alias := range sub.Aliases {
+				if !sub.IsDeprecatedAlias(alias) {
+					candidates = append(candidates, completionCandidate(alias, sub.Short))
+				}
+			}
+		}
+		for _, shortcut := range cmd.Shortcuts() {
+			if shortcut.Target.IsAvailableCommand() {
+				candidates = append(candidates, completionCandidate(shortcut.Name, "Shortcut for "+shortcut.Target.CommandPath()))
+			}
+		}
+		if !cmd.HasParent() {
+			aliases, _ := cmd.UserAliases()
+			for name, expansion := range aliases {
+				candidates = append(candidates, completionCandidate(name, "Alias for "+expansion))
+			}
+		}
+	} else {
+		directive = ShellCompDirectiveDefault
+	}
+
+	if directive&(ShellCompDirectiveFilterFileExt|ShellCompDirectiveFilterDirs) != 0 {
+		// The candidates are not values to complete.
+		return candidates, directive
+	}
+	matches := matchCandidates(candidates, toComplete)
+	sort.Strings(matches)
+	return matches, directive
+}
+
+// matchCandidates returns the candidates that start with toComplete.
+func matchCandidates(candidates []string, toComplete string) []string {
+	var matches []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+// completionCandidate returns value with the first line of description in
+// the form of a candidate, "value\tdescription".
+func completionCandidate(value, description string) string {
+	description = strings.TrimSpace(strings.SplitN(description, "\n", 2)[0])
+	if len(description) == 0 {
+		return value
+	}
+	return value + "\t" + strings.Replace(description, "\t", " ", -1)
+}
+
+// flagValueCandidates returns the candidates for the value of f, each
+// prefixed with prefix, and how the shell should complete them. Only the
+// values of enum flags can be completed after a prefix, like "--flag=".
+func flagValueCandidates(f *flag.Flag, prefix string) ([]string, ShellCompDirective) {
+	if values := flagEnumValues(f); len(values) > 0 {
+		candidates := make([]string, len(values))
+		for i, v := range values {
+			candidates[i] = prefix + v
+		}
+		return candidates, ShellCompDirectiveNoFileComp
+	}
+	if len(prefix) > 0 {
+		return nil, ShellCompDirectiveDefault
+	}
+	if exts, ok := f.Annotations[BashCompFilenameExt]; ok && len(exts) > 0 {
+		return exts, ShellCompDirectiveFilterFileExt
+	}
+	if dirs, ok := f.Annotations[BashCompSubdirsInDir]; ok {
+		if len(dirs) == 1 {
+			return dirs, ShellCompDirectiveFilterDirs
+		}
+		return nil, ShellCompDirectiveFilterDirs
+	}
+	return nil, ShellCompDirectiveDefault
+}
+
+// completionFlags returns the long and short forms of the flags of cmd
+// that are offered for completion after args, with their usage. The
+// required flags that are not in args come first. Flags that are in args
+// are left out unless they are repeatable, and so are the flags that are
+// mutually exclusive with a flag in args.
+func completionFlags(cmd *Command, args []string) []string {
+	used := usedFlags(cmd, args)
+	hidden := make(map[string]bool)
+	for name := range used {
+		for _, conflict := range cmd.flagConflicts(name) {
+			hidden[conflict] = true
+		}
+	}
+
+	var required, optional []string
+	cmd.Flags().VisitAll(func(f *flag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 || !cmd.FlagAllowed(f) {
+			return
+		}
+		if hidden[f.Name] || (used[f.Name] && !FlagRepeatable(f)) {
+			return
+		}
+		names := []string{completionCandidate("--"+f.Name, f.Usage)}
+		if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+			names = append(names, completionCandidate("-"+f.Shorthand, f.Usage))
+		}
+		if FlagRequired(f) && !used[f.Name] {
+			required = append(required, names...)
+		} else {
+			optional = append(optional, names...)
+		}
+	})
+	sort.Strings(required)
+	sort.Strings(optional)
+	return append(required, optional...)
+}
+
+// usedFlags returns the names of the flags of cmd that are set in args.
+func usedFlags(cmd *Command, args []string) map[string]bool {
+	used := make(map[string]bool)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || len(arg) < 2 {
+			continue
+		}
+		if strings.HasPrefix(arg, "--") {
+			name := strings.SplitN(arg[2:], "=", 2)[0]
+			if f := cmd.Flags().Lookup(name); f != nil {
+				used[f.Name] = true
+				if !strings.Contains(arg, "=") && len(f.NoOptDefVal) == 0 {
+					i++
+				}
+			}
+			continue
+		}
+		// Shorthands can be combined, like -vo json, up to the first
+		// one that takes a value.
+		for j := 1; j < len(arg); j++ {
+			f := cmd.Flags().ShorthandLookup(arg[j : j+1])
+			if f == nil {
+				break
+			}
+			used[f.Name] = true
+			if len(f.NoOptDefVal) == 0 {
+				if j == len(arg)-1 {
+					i++
+				}
+				break
+			}
+		}
+	}
+	return used
+}
+
+// flagExpectingValue returns the flag of cmd that the last of args names if
+// the flag takes its value from the next word, or nil.
+func flagExpectingValue(cmd *Command, args []string) *flag.Flag {
+	if len(args) == 0 {
+		return nil
+	}
+	last := args[len(args)-1]
+	if strings.Contains(last, "=") {
+		return nil
+	}
+	f := lookupCompletionFlag(cmd, last)
+	if f == nil || len(f.NoOptDefVal) > 0 {
+		return nil
+	}
+	return f
+}
+
+// lookupCompletionFlag returns the flag of cmd that arg, a long or short
+// flag without a value, names, or nil.
+func lookupCompletionFlag(cmd *Command, arg string) *flag.Flag {
+	if strings.HasPrefix(arg, "--") && len(arg) > 2 {
+		return cmd.Flags().Lookup(arg[2:])
+	}
+	if strings.HasPrefix(arg, "-") && len(arg) == 2 {
+		return cmd.Flags().ShorthandLookup(arg[1:])
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package cobra
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompletions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("config", "c", "", "")
+	childCmd := &Command{Use: "child", Aliases: []string{"kid"}, ValidArgs: []string{"one\tThe first", "two"}, Run: emptyRun}
+	childCmd.Flags().Bool("bool", false, "")
+	childCmd.Flags().String("hidden", "", "")
+	childCmd.Flags().MarkHidden("hidden")
+	childCmd.Flags().String("old", "", "")
+	childCmd.Flags().MarkDeprecated("old", "do not use")
+	rootCmd.AddCommand(childCmd,
+		&Command{Use: "chat", Run: emptyRun},
+		&Command{Use: "secret", Hidden: true, Run: emptyRun},
+		&Command{Use: "deprecated", Deprecated: "do not use", Run: emptyRun},
+	)
+	rootCmd.InitDefaultHelpCmd()
+
+	tests := []struct {
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{nil, "", []string{"chat", "child", "help", "kid"}},
+		{nil, "ch", []string{"chat", "child"}},
+		{nil, "-", []string{"--config", "--help", "-c", "-h"}},
+		{[]string{"child"}, "", []string{"one", "two"}},
+		{[]string{"kid", "--bool"}, "t", []string{"two"}},
+		{[]string{"child"}, "--", []string{"--bool", "--config", "--help"}},
+		{[]string{"child", "--config"}, "", nil},
+		{[]string{"child", "-c"}, "", nil},
+		{[]string{"chat", "arg"}, "", nil},
+	}
+	for _, tc := range tests {
+		got := rootCmd.completions(tc.args, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q %q: expected %q, got %q", tc.args, tc.toComplete, tc.expected, got)
+		}
+	}
+}
+
+func TestCompletionsUsedAndRequiredFlags(t *testing.T) {
+	rootCmd := newFlagGroupsTestRoot()
+
+	tests := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"get"}, []string{"--output", "-o", "--help", "--json", "--tag", "--verbose", "--yaml", "-h", "-t", "-v"}},
+		{[]string{"get", "-o", "out"}, []string{"--help", "--json", "--tag", "--verbose", "--yaml", "-h", "-t", "-v"}},
+		{[]string{"get", "--output=out", "--json", "-vt", "a"}, []string{"--help", "--tag", "-h", "-t"}},
+		{[]string{"get", "--yaml", "--", "--verbose"}, []string{"--output", "-o", "--help", "--tag", "--verbose", "-h", "-t", "-v"}},
+	}
+	for _, tc := range tests {
+		got := rootCmd.completions(tc.args, "-")
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q: expected %q, got %q", tc.args, tc.expected, got)
+		}
+	}
+}
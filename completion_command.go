@@ -0,0 +1,196 @@
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// completionShell describes how to generate the completion script of a
+// shell and where to install it.
+type completionShell struct {
+	name string
+	// generate writes the script for root to w.
+	generate func(root *Command, w io.Writer, descriptions bool) error
+	// path returns the file the script for root is installed to.
+	path func(root *Command) (string, error)
+	// activation explains how to activate the installed script in dir.
+	activation func(root *Command, dir string) string
+	// load explains how to load the script without installing it.
+	load string
+}
+
+var completionShells = []completionShell{
+	{
+		name: "bash",
+		generate: func(root *Command, w io.Writer, descriptions bool) error {
+			return root.GenBashCompletionV2(w, descriptions)
+		},
+		path: func(root *Command) (string, error) {
+			dir, err := xdgDataHome()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, "bash-completion", "completions", root.Name()), nil
+		},
+		activation: func(root *Command, dir string) string {
+			return fmt.Sprintf("The completion is loaded by bash-completion 2 in new shells. With older versions, add\n\n"+
+				"    source %s\n\nto your ~/.bashrc.\n", filepath.Join(dir, root.Name()))
+		},
+		load: "To load the completion in the current shell, run:\n\n    source <(%[1]s completion bash)",
+	},
+	{
+		name: "zsh",
+		generate: func(root *Command, w io.Writer, descriptions bool) error {
+			if descriptions {
+				return root.GenZshCompletion(w)
+			}
+			return root.GenZshCompletionNoDesc(w)
+		},
+		path: func(root *Command) (string, error) {
+			dir, err := xdgDataHome()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, "zsh", "site-functions", "_"+root.Name()), nil
+		},
+		activation: func(root *Command, dir string) string {
+			return fmt.Sprintf("If %s is not in your fpath yet, add\n\n"+
+				"    fpath=(%s $fpath)\n\nto your ~/.zshrc before compinit is called. "+
+				"The completion is loaded in new shells.\n", dir, dir)
+		},
+		load: "To load the completion in new shells, write it to a directory in your fpath:\n\n" +
+			"    %[1]s completion zsh > \"${fpath[1]}/_%[1]s\"",
+	},
+	{
+		name: "nushell",
+		generate: func(root *Command, w io.Writer, descriptions bool) error {
+			return root.genNushellCompletion(w, descriptions)
+		},
+		path: func(root *Command) (string, error) {
+			dir, err := xdgDataHome()
+			if err != nil {
+				return "", err
+			}
+			return filepath.Join(dir, "nushell", "vendor", "autoload", root.Name()+".nu"), nil
+		},
+		activation: func(root *Command, dir string) string {
+			return fmt.Sprintf("The completion is loaded by Nushell 0.101 and later in new shells. With older versions, add\n\n"+
+				"    use %s *\n\nto your config.nu.\n", filepath.Join(dir, root.Name()+".nu"))
+		},
+		load: "To load the completion in new shells, save it with\n\n" +
+			"    %[1]s completion nushell | save -f ($nu.default-config-dir | path join %[1]s.nu)\n\n" +
+			"and add \"use %[1]s.nu *\" to your config.nu.",
+	},
+}
+
+// xdgDataHome returns the directory for user data files of the XDG Base
+// Directory Specification.
+func xdgDataHome() (string, error) {
+	if dir := os.Getenv("XDG_DATA_HOME"); len(dir) > 0 {
+		return dir, nil
+	}
+	home := os.Getenv("HOME")
+	if len(home) == 0 {
+		return "", fmt.Errorf("neither XDG_DATA_HOME nor HOME is set")
+	}
+	return filepath.Join(home, ".local", "share"), nil
+}
+
+// InitDefaultCompletionCmd adds a "completion" command to c, unless c already
+// has a command of that name. It has a subcommand for each supported shell,
+// which prints the completion script for the tree of c, and those have
+// "install" and "uninstall" subcommands, which write the script to, or
+// remove it from, the directory the shell loads completions from. The
+// --no-descriptions flag leaves the descriptions of arguments and flags out
+// of the script.
+func (c *Command) InitDefaultCompletionCmd() {
+	if !c.HasSubCommands() || c.findNext("completion") != nil {
+		return
+	}
+
+	completionCmd := &Command{
+		Use:   "completion",
+		Short: "Generate the autocompletion script for a shell",
+		Long: fmt.Sprintf(`Generate the autocompletion script of %[1]s for a shell.
+
+Run "%[1]s completion <shell> install" to install the script for your user,
+or see "%[1]s completion <shell> --help" to load it in other ways.`, c.Name()),
+		Args: NoArgs,
+	}
+	completionCmd.PersistentFlags().Bool("no-descriptions", false, "leave descriptions out of the completion script")
+
+	for _, shell := range completionShells {
+		completionCmd.AddCommand(newCompletionShellCmd(c, shell))
+	}
+	c.AddCommand(completionCmd)
+}
+
+func newCompletionShellCmd(root *Command, shell completionShell) *Command {
+	descriptions := func(cmd *Command) bool {
+		noDesc, _ := cmd.Flags().GetBool("no-descriptions")
+		return !noDesc
+	}
+
+	shellCmd := &Command{
+		Use:   shell.name,
+		Short: fmt.Sprintf("Generate the autocompletion script for %s", shell.name),
+		Long: fmt.Sprintf("Generate the autocompletion script of %[1]s for %[2]s.\n\n"+shell.load+
+			"\n\nTo install it for your user instead, run \"%[1]s completion %[2]s install\".", root.Name(), shell.name),
+		Args: NoArgs,
+		RunE: func(cmd *Command, args []string) error {
+			return shell.generate(cmd.Root(), cmd.OutOrStdout(), descriptions(cmd))
+		},
+	}
+
+	installCmd := &Command{
+		Use:   "install",
+		Short: fmt.Sprintf("Install the autocompletion script for %s", shell.name),
+		Args:  NoArgs,
+		RunE: func(cmd *Command, args []string) error {
+			path, err := shell.path(cmd.Root())
+			if err != nil {
+				return err
+			}
+			buf := new(bytes.Buffer)
+			if err := shell.generate(cmd.Root(), buf, descriptions(cmd)); err != nil {
+				return err
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			if err := ioutil.WriteFile(path, buf.Bytes(), 0644); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Installed the %s completion to %s.\n%s",
+				shell.name, path, shell.activation(cmd.Root(), filepath.Dir(path)))
+			return nil
+		},
+	}
+
+	uninstallCmd := &Command{
+		Use:   "uninstall",
+		Short: fmt.Sprintf("Uninstall the autocompletion script for %s", shell.name),
+		Args:  NoArgs,
+		RunE: func(cmd *Command, args []string) error {
+			path, err := shell.path(cmd.Root())
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); os.IsNotExist(err) {
+				fmt.Fprintf(cmd.OutOrStdout(), "The %s completion is not installed at %s.\n", shell.name, path)
+				return nil
+			} else if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed the %s completion from %s.\n", shell.name, path)
+			return nil
+		},
+	}
+
+	shellCmd.AddCommand(installCmd, uninstallCmd)
+	return shellCmd
+}
@@ -0,0 +1,124 @@
+package cobra
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompletionCmdScripts(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "get", ValidArgs: []string{"pod\tA group of containers"}, Run: emptyRun})
+	rootCmd.InitDefaultCompletionCmd()
+
+	output, err := executeCommand(rootCmd, "completion", "bash")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "# bash completion V2 for app")
+	checkStringContains(t, output, ShellCompRequestCmd+" ")
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "completion", "bash", "--no-descriptions")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, ShellCompNoDescRequestCmd+" ")
+	checkStringOmits(t, output, ShellCompRequestCmd+" ")
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "completion", "zsh")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "#compdef app")
+	checkStringContains(t, output, "'pod:A group of containers'")
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "completion", "zsh", "--no-descriptions")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "args=('pod')")
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "completion", "nushell")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "# nushell completion for app")
+	checkStringContains(t, output, `{ value: "pod", description: "A group of containers" }`)
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "completion", "nushell", "--no-descriptions")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, `{ value: "pod" }`)
+	checkStringOmits(t, output, "A group of containers")
+}
+
+func TestCompletionCmdExisting(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	completionCmd := &Command{Use: "completion", Run: emptyRun}
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.InitDefaultCompletionCmd()
+
+	if len(rootCmd.Commands()) != 1 || rootCmd.Commands()[0] != completionCmd {
+		t.Error("Expected the existing completion command to be kept")
+	}
+}
+
+func TestCompletionCmdInstall(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "cobra-completion")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	os.Setenv("XDG_DATA_HOME", tmpdir)
+	defer os.Unsetenv("XDG_DATA_HOME")
+
+	tests := []struct {
+		shell   string
+		path    string
+		content string
+	}{
+		{"bash", filepath.Join(tmpdir, "bash-completion", "completions", "app"), "# bash completion V2 for app"},
+		{"zsh", filepath.Join(tmpdir, "zsh", "site-functions", "_app"), "#compdef app"},
+		{"nushell", filepath.Join(tmpdir, "nushell", "vendor", "autoload", "app.nu"), "# nushell completion for app"},
+	}
+	for _, tc := range tests {
+		rootCmd := &Command{Use: "app", Run: emptyRun}
+		rootCmd.AddCommand(&Command{Use: "get", Run: emptyRun})
+		rootCmd.InitDefaultCompletionCmd()
+
+		output, err := executeCommand(rootCmd, "completion", tc.shell, "install")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.shell, err)
+		}
+		checkStringContains(t, output, "Installed the "+tc.shell+" completion to "+tc.path)
+		script, err := ioutil.ReadFile(tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.shell, err)
+		}
+		checkStringContains(t, string(script), tc.content)
+
+		rootCmd.ResetFlagsState()
+		output, err = executeCommand(rootCmd, "completion", tc.shell, "uninstall")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.shell, err)
+		}
+		checkStringContains(t, output, "Removed the "+tc.shell+" completion from "+tc.path)
+		if _, err := os.Stat(tc.path); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the script to be removed, got %v", tc.shell, err)
+		}
+
+		rootCmd.ResetFlagsState()
+		output, err = executeCommand(rootCmd, "completion", tc.shell, "uninstall")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.shell, err)
+		}
+		checkStringContains(t, output, "The "+tc.shell+" completion is not installed at "+tc.path)
+	}
+}
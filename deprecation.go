@@ -0,0 +1,87 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DeprecationWarningsEnv is the name of the environment variable that, when
+// set to a non-empty value, silences the warnings printed when deprecated
+// commands, aliases, flags or arguments are used.
+var DeprecationWarningsEnv = "COBRA_NO_DEPRECATION_WARNINGS"
+
+// DeprecationMessage returns the message shown when the deprecated command c is
+// used. It is made of Deprecated and, if they are set, the replacement command
+// and the version in which c will be removed.
+func (c *Command) DeprecationMessage() string {
+	msg := c.Deprecated
+	if len(c.DeprecatedReplacement) > 0 {
+		msg += fmt.Sprintf("; use %q instead", c.Root().Name()+" "+c.DeprecatedReplacement)
+	}
+	if len(c.DeprecatedRemovalVersion) > 0 {
+		msg += fmt.Sprintf("; it will be removed in %s", c.DeprecatedRemovalVersion)
+	}
+	return msg
+}
+
+// IsDeprecatedAlias returns true if alias is a deprecated alias of c.
+func (c *Command) IsDeprecatedAlias(alias string) bool {
+	_, ok := c.DeprecatedAliases[alias]
+	return ok
+}
+
+// IsDeprecatedValidArg returns true if arg is a deprecated entry of ValidArgs.
+func (c *Command) IsDeprecatedValidArg(arg string) bool {
+	_, ok := c.DeprecatedValidArgs[arg]
+	return ok
+}
+
+// ForwardedCommand returns the command that runs when c is executed: the
+// command named by DeprecatedReplacement if c is deprecated and has
+// DeprecatedForward set, or c itself otherwise.
+func (c *Command) ForwardedCommand() (*Command, error) {
+	if len(c.Deprecated) == 0 || !c.DeprecatedForward {
+		return c, nil
+	}
+
+	path := strings.Fields(c.DeprecatedReplacement)
+	if len(path) == 0 {
+		return nil, fmt.Errorf("deprecated command %q has no replacement to forward to", c.CommandPath())
+	}
+
+	root := c.Root()
+	target, args, err := root.Find(path)
+	if err != nil || target == root || len(args) > 0 {
+		return nil, fmt.Errorf("replacement %q of deprecated command %q not found", c.DeprecatedReplacement, c.CommandPath())
+	}
+	return target, nil
+}
+
+// warnDeprecatedArgs warns about the deprecated ValidArgs in args.
+func (c *Command) warnDeprecatedArgs(args []string) {
+	for _, arg := range args {
+		if msg, ok := c.DeprecatedValidArgs[arg]; ok {
+			c.warnDeprecated(fmt.Sprintf("Argument %q is deprecated, %s", arg, msg))
+		}
+	}
+}
+
+// warnDeprecated prints the deprecation warning msg to the error output,
+// unless it was already printed during the current execution or warnings
+// are silenced through DeprecationWarningsEnv.
+func (c *Command) warnDeprecated(msg string) {
+	if len(os.Getenv(DeprecationWarningsEnv)) > 0 {
+		return
+	}
+
+	root := c.Root()
+	if root.deprecationWarnings[msg] {
+		return
+	}
+	if root.deprecationWarnings == nil {
+		root.deprecationWarnings = make(map[string]bool)
+	}
+	root.deprecationWarnings[msg] = true
+	c.PrintErrln(msg)
+}
@@ -0,0 +1,219 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func executeCommandSplit(root *Command, args ...string) (stdout, stderr string, c *Command, err error) {
+	outBuf := new(bytes.Buffer)
+	errBuf := new(bytes.Buffer)
+	root.SetOut(outBuf)
+	root.SetErr(errBuf)
+	root.SetArgs(args)
+
+	c, err = root.ExecuteC()
+	return outBuf.String(), errBuf.String(), c, err
+}
+
+func TestDeprecatedCommandWarnsOnStderr(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	oldCmd := &Command{
+		Use:                      "old",
+		Deprecated:               "it is no longer maintained",
+		DeprecatedReplacement:    "new",
+		DeprecatedRemovalVersion: "v2.0.0",
+		Run:                      emptyRun,
+	}
+	rootCmd.AddCommand(oldCmd, &Command{Use: "new", Run: emptyRun})
+
+	stdout, stderr, _, err := executeCommandSplit(rootCmd, "old")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if stdout != "" {
+		t.Errorf("Expected empty stdout, got %q", stdout)
+	}
+	expected := `Command "old" is deprecated, it is no longer maintained; use "root new" instead; it will be removed in v2.0.0` + "\n"
+	if stderr != expected {
+		t.Errorf("Expected stderr %q, got %q", expected, stderr)
+	}
+}
+
+func TestDeprecatedCommandForward(t *testing.T) {
+	var gotArgs []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	configCmd := &Command{Use: "config"}
+	setCmd := &Command{
+		Use: "set",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	setCmd.Flags().Bool("force", false, "")
+	oldCmd := &Command{
+		Use:                   "set-config",
+		Deprecated:            "it was moved",
+		DeprecatedReplacement: "config set",
+		DeprecatedForward:     true,
+		Run: func(*Command, []string) {
+			t.Error("Deprecated command should not run")
+		},
+	}
+	configCmd.AddCommand(setCmd)
+	rootCmd.AddCommand(configCmd, oldCmd)
+
+	_, stderr, c, err := executeCommandSplit(rootCmd, "set-config", "--force", "a", "b")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c != setCmd {
+		t.Errorf("Expected executed command to be %q, got %q", setCmd.CommandPath(), c.CommandPath())
+	}
+	if strings.Join(gotArgs, " ") != "a b" {
+		t.Errorf("Expected args [a b], got %v", gotArgs)
+	}
+	if force, _ := setCmd.Flags().GetBool("force"); !force {
+		t.Error("Expected --force to be forwarded")
+	}
+	checkStringContains(t, stderr, `Command "set-config" is deprecated, it was moved; use "root config set" instead`)
+}
+
+func TestForwardedCommand(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	newCmd := &Command{Use: "new", Run: emptyRun}
+	oldCmd := &Command{Use: "old", Deprecated: "it was renamed", DeprecatedReplacement: "new", DeprecatedForward: true, Run: emptyRun}
+	rootCmd.AddCommand(newCmd, oldCmd)
+
+	for cmd, expected := range map[*Command]*Command{oldCmd: newCmd, newCmd: newCmd, rootCmd: rootCmd} {
+		if got, err := cmd.ForwardedCommand(); err != nil || got != expected {
+			t.Errorf("%s: expected %q, got %v, %v", cmd.Name(), expected.Name(), got, err)
+		}
+	}
+}
+
+func TestDeprecatedCommandForwardMissingReplacement(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{
+		Use:                   "old",
+		Deprecated:            "it was moved",
+		DeprecatedReplacement: "missing",
+		DeprecatedForward:     true,
+		Run:                   emptyRun,
+	})
+
+	_, _, _, err := executeCommandSplit(rootCmd, "old")
+	if err == nil || err.Error() != `replacement "missing" of deprecated command "root old" not found` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestDeprecatedAlias(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	listCmd := &Command{
+		Use:               "list",
+		Aliases:           []string{"ls", "dir"},
+		DeprecatedAliases: map[string]string{"dir": `use "ls" instead`},
+		Run:               emptyRun,
+	}
+	rootCmd.AddCommand(listCmd)
+
+	_, stderr, _, err := executeCommandSplit(rootCmd, "dir")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := `Alias "dir" for command "list" is deprecated, use "ls" instead` + "\n"; stderr != expected {
+		t.Errorf("Expected stderr %q, got %q", expected, stderr)
+	}
+
+	rootCmd.ResetFlagsState()
+	if _, stderr, _, _ = executeCommandSplit(rootCmd, "ls"); stderr != "" {
+		t.Errorf("Expected no warning for alias ls, got %q", stderr)
+	}
+
+	checkStringContains(t, listCmd.UsageString(), "list, ls, dir (deprecated)")
+
+	if got := strings.Join(rootCmd.completions(nil, ""), " "); got != "help list ls" {
+		t.Errorf("Expected completions %q, got %q", "help list ls", got)
+	}
+
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletion(buf)
+	checkOmit(t, buf.String(), `command_aliases+=("dir")`)
+	check(t, buf.String(), `command_aliases+=("ls")`)
+}
+
+func TestDeprecatedValidArgs(t *testing.T) {
+	rootCmd := &Command{
+		Use:                 "root",
+		ValidArgs:           []string{"pod", "node", "minion"},
+		DeprecatedValidArgs: map[string]string{"minion": `use "node" instead`},
+		Args:                OnlyValidArgs,
+		Run:                 emptyRun,
+	}
+
+	_, stderr, _, err := executeCommandSplit(rootCmd, "minion", "pod")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if expected := `Argument "minion" is deprecated, use "node" instead` + "\n"; stderr != expected {
+		t.Errorf("Expected stderr %q, got %q", expected, stderr)
+	}
+
+	if got := strings.Join(rootCmd.completions(nil, ""), " "); got != "node pod" {
+		t.Errorf("Expected completions %q, got %q", "node pod", got)
+	}
+
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletion(buf)
+	checkOmit(t, buf.String(), `must_have_one_noun+=("minion")`)
+}
+
+func TestDeprecationWarningsOncePerExecution(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringSlice("old", nil, "")
+	rootCmd.Flags().MarkDeprecated("old", "use --new instead")
+
+	_, stderr, _, err := executeCommandSplit(rootCmd, "--old", "a", "--old", "b")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	expected := "Flag --old has been deprecated, use --new instead\n"
+	if stderr != expected {
+		t.Errorf("Expected stderr %q, got %q", expected, stderr)
+	}
+
+	rootCmd.ResetFlagsState()
+	if _, stderr, _, _ = executeCommandSplit(rootCmd, "--old", "a"); stderr != expected {
+		t.Errorf("Expected the warning to be printed again on the next execution, got %q", stderr)
+	}
+}
+
+func TestDeprecationWarningsEnv(t *testing.T) {
+	os.Setenv(DeprecationWarningsEnv, "1")
+	defer os.Unsetenv(DeprecationWarningsEnv)
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "old", Deprecated: "do not use", Run: emptyRun})
+
+	if _, stderr, _, _ := executeCommandSplit(rootCmd, "old"); stderr != "" {
+		t.Errorf("Expected no warnings, got %q", stderr)
+	}
+}
+
+func TestDeprecatedCommandHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{
+		Use:                      "old",
+		Short:                    "An old command",
+		Deprecated:               "do not use",
+		DeprecatedRemovalVersion: "v2.0.0",
+		Run:                      emptyRun,
+	})
+
+	stdout, _, _, err := executeCommandSplit(rootCmd, "help", "old")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, stdout, "DEPRECATED: do not use; it will be removed in v2.0.0\n\nAn old command")
+}
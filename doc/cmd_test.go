@@ -65,6 +65,8 @@ var deprecatedCmd = &cobra.Command{
 	Short:      "A command which is deprecated",
 	Long:       `an absolutely utterly useless command for testing deprecation!.`,
 	Deprecated: "Please use echo instead",
+
+	DeprecatedRemovalVersion: "v2.0.0",
 }
 
 var printCmd = &cobra.Command{
@@ -0,0 +1,412 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// specFlag is a flag of a command, as completion specs describe it.
+type specFlag struct {
+	long, short string
+	usage       string
+	// takesValue is true if the flag needs a value, and optionalValue if it
+	// may be given one.
+	takesValue, optionalValue bool
+	persistent                bool
+	required                  bool
+	repeatable                bool
+	// conflicts are the names of the flags that are mutually exclusive with
+	// this one.
+	conflicts []string
+	// values are the values of an enum flag.
+	values []string
+	// files is true if the value is a file name with one of extensions, or
+	// any file name if there are none.
+	files      bool
+	extensions []string
+	// dirs is true if the value is a directory, in dir if it is set.
+	dirs bool
+	dir  string
+}
+
+// specArg is a positional argument of a command.
+type specArg struct {
+	name               string
+	optional, variadic bool
+}
+
+// specCommand is a command, as completion specs describe it.
+type specCommand struct {
+	name        string
+	aliases     []string
+	description string
+	flags       []specFlag
+	args        []specArg
+	// validArgs are the ValidArgs of the command, which may be followed by a
+	// tab and a description.
+	validArgs   []string
+	subcommands []*specCommand
+}
+
+// newSpecCommand walks cmd and its available subcommands.
+func newSpecCommand(cmd *cobra.Command) *specCommand {
+	cmd.InitDefaultHelpFlag()
+	sc := &specCommand{
+		name:        cmd.Name(),
+		description: cmd.Short,
+		args:        specArgs(cmd),
+	}
+	for _, alias := range cmd.Aliases {
+		if !cmd.IsDeprecatedAlias(alias) {
+			sc.aliases = append(sc.aliases, alias)
+		}
+	}
+	for _, arg := range cmd.ValidArgs {
+		if !cmd.IsDeprecatedValidArg(strings.SplitN(arg, "\t", 2)[0]) {
+			sc.validArgs = append(sc.validArgs, arg)
+		}
+	}
+
+	cmd.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 || !cmd.FlagAllowed(f) {
+			return
+		}
+		sc.flags = append(sc.flags, newSpecFlag(cmd, f))
+	})
+
+	for _, c := range cmd.Commands() {
+		if !c.IsAvailableCommand() || c.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		sc.subcommands = append(sc.subcommands, newSpecCommand(c))
+	}
+	return sc
+}
+
+func newSpecFlag(cmd *cobra.Command, f *pflag.Flag) specFlag {
+	sf := specFlag{
+		long:          "--" + f.Name,
+		usage:         f.Usage,
+		takesValue:    len(f.NoOptDefVal) == 0,
+		optionalValue: len(f.NoOptDefVal) > 0 && f.Value.Type() != "bool" && f.Value.Type() != "count",
+		persistent:    cmd.PersistentFlags().Lookup(f.Name) != nil,
+		required:      cobra.FlagRequired(f),
+		repeatable:    cobra.FlagRepeatable(f),
+		values:        f.Annotations[cobra.FlagEnumAnnotation],
+	}
+	if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+		sf.short = "-" + f.Shorthand
+	}
+	for _, group := range cmd.MutuallyExclusiveFlagGroups() {
+		if !containsString(group, f.Name) {
+			continue
+		}
+		for _, name := range group {
+			if name != f.Name {
+				sf.conflicts = append(sf.conflicts, name)
+			}
+		}
+	}
+	if exts, ok := f.Annotations[cobra.BashCompFilenameExt]; ok {
+		sf.files = true
+		sf.extensions = exts
+	}
+	if dirs, ok := f.Annotations[cobra.BashCompSubdirsInDir]; ok {
+		sf.dirs = true
+		if len(dirs) == 1 {
+			sf.dir = dirs[0]
+		}
+	}
+	return sf
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// argCounts returns the smallest and largest number of positional arguments
+// cmd accepts, with -1 as the largest if there is no limit. Commands whose
+// counts are unknown, as their Args is a custom function without
+// cobra.ArgCountAnnotation, are taken to accept any number.
+func argCounts(cmd *cobra.Command) (min, max int) {
+	if !cmd.Runnable() {
+		return 0, 0
+	}
+	min, max, _ = cmd.ArgCounts()
+	return min, max
+}
+
+// useArgPattern matches the words of Use, with placeholders in brackets
+// kept together.
+var useArgPattern = regexp.MustCompile(`\[[^\]]*\]|<[^>]*>|\S+`)
+
+// specArgs returns the positional arguments of cmd, named after the
+// placeholders in its Use line.
+func specArgs(cmd *cobra.Command) []specArg {
+	var names []string
+	words := useArgPattern.FindAllString(cmd.Use, -1)
+	if len(words) > 0 {
+		// The first word is the name of the command.
+		words = words[1:]
+	}
+	for _, word := range words {
+		name := strings.TrimSuffix(strings.Trim(word, "[]<>"), "...")
+		if len(name) > 0 && name != "flags" {
+			names = append(names, name)
+		}
+	}
+	nameAt := func(i int) string {
+		if i < len(names) {
+			return names[i]
+		}
+		if len(names) > 0 {
+			return names[len(names)-1]
+		}
+		return "arg"
+	}
+
+	min, max := argCounts(cmd)
+	var args []specArg
+	for i := 0; i < min; i++ {
+		args = append(args, specArg{name: nameAt(i)})
+	}
+	if max < 0 {
+		if min == 0 || min < len(names) {
+			args = append(args, specArg{name: nameAt(min), optional: true})
+		}
+		args[len(args)-1].variadic = true
+		return args
+	}
+	for i := min; i < max; i++ {
+		args = append(args, specArg{name: nameAt(i), optional: true})
+	}
+	return args
+}
+
+// validArgParts returns the value and description of an entry of ValidArgs.
+func validArgParts(arg string) (value, description string) {
+	parts := strings.SplitN(arg, "\t", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
+
+// figSpec is a command of a Fig completion spec.
+type figSpec struct {
+	Name        interface{} `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Subcommands []*figSpec  `json:"subcommands,omitempty"`
+	Options     []figOption `json:"options,omitempty"`
+	Args        []figArg    `json:"args,omitempty"`
+}
+
+type figOption struct {
+	Name         interface{} `json:"name"`
+	Description  string      `json:"description,omitempty"`
+	Args         *figArg     `json:"args,omitempty"`
+	IsPersistent bool        `json:"isPersistent,omitempty"`
+	IsRequired   bool        `json:"isRequired,omitempty"`
+	IsRepeatable bool        `json:"isRepeatable,omitempty"`
+	ExclusiveOn  []string    `json:"exclusiveOn,omitempty"`
+}
+
+type figArg struct {
+	Name        string          `json:"name,omitempty"`
+	IsOptional  bool            `json:"isOptional,omitempty"`
+	IsVariadic  bool            `json:"isVariadic,omitempty"`
+	Suggestions []figSuggestion `json:"suggestions,omitempty"`
+	Template    string          `json:"template,omitempty"`
+}
+
+type figSuggestion struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// figNames returns name, or name followed by aliases if there are any.
+func figNames(name string, aliases ...string) interface{} {
+	if len(aliases) == 0 {
+		return name
+	}
+	return append([]string{name}, aliases...)
+}
+
+func (sc *specCommand) fig() *figSpec {
+	spec := &figSpec{Name: figNames(sc.name, sc.aliases...), Description: sc.description}
+	for _, sub := range sc.subcommands {
+		spec.Subcommands = append(spec.Subcommands, sub.fig())
+	}
+
+	for _, f := range sc.flags {
+		opt := figOption{
+			Name:         f.long,
+			Description:  f.usage,
+			IsPersistent: f.persistent,
+			IsRequired:   f.required,
+			IsRepeatable: f.repeatable,
+		}
+		if len(f.short) > 0 {
+			opt.Name = []string{f.long, f.short}
+		}
+		for _, name := range f.conflicts {
+			opt.ExclusiveOn = append(opt.ExclusiveOn, "--"+name)
+		}
+		if f.takesValue || f.optionalValue {
+			arg := &figArg{Name: strings.TrimPrefix(f.long, "--"), IsOptional: f.optionalValue}
+			for _, v := range f.values {
+				arg.Suggestions = append(arg.Suggestions, figSuggestion{Name: v})
+			}
+			if f.files {
+				arg.Template = "filepaths"
+			} else if f.dirs {
+				arg.Template = "folders"
+			}
+			opt.Args = arg
+		}
+		spec.Options = append(spec.Options, opt)
+	}
+
+	var suggestions []figSuggestion
+	for _, arg := range sc.validArgs {
+		value, description := validArgParts(arg)
+		suggestions = append(suggestions, figSuggestion{Name: value, Description: description})
+	}
+	for _, a := range sc.args {
+		spec.Args = append(spec.Args, figArg{
+			Name:        a.name,
+			IsOptional:  a.optional,
+			IsVariadic:  a.variadic,
+			Suggestions: suggestions,
+		})
+	}
+	return spec
+}
+
+// carapaceSpec is a command of a Carapace spec.
+type carapaceSpec struct {
+	Name            string             `yaml:"name"`
+	Aliases         []string           `yaml:"aliases,omitempty"`
+	Description     string             `yaml:"description,omitempty"`
+	Flags           yaml.MapSlice      `yaml:"flags,omitempty"`
+	PersistentFlags yaml.MapSlice      `yaml:"persistentflags,omitempty"`
+	ExclusiveFlags  [][]string         `yaml:"exclusiveflags,omitempty"`
+	Completion      carapaceCompletion `yaml:"completion,omitempty"`
+	Commands        []*carapaceSpec    `yaml:"commands,omitempty"`
+}
+
+type carapaceCompletion struct {
+	Flag          yaml.MapSlice `yaml:"flag,omitempty"`
+	Positional    [][]string    `yaml:"positional,omitempty"`
+	PositionalAny []string      `yaml:"positionalany,omitempty"`
+}
+
+func (sc *specCommand) carapace() *carapaceSpec {
+	spec := &carapaceSpec{Name: sc.name, Aliases: sc.aliases, Description: sc.description}
+	for _, sub := range sc.subcommands {
+		spec.Commands = append(spec.Commands, sub.carapace())
+	}
+
+	seen := make(map[string]bool)
+	for _, f := range sc.flags {
+		// e.g. "-o, --output=*!": a repeatable, required flag with a value.
+		key := f.long
+		if len(f.short) > 0 {
+			key = f.short + ", " + key
+		}
+		if f.takesValue {
+			key += "="
+		} else if f.optionalValue {
+			key += "?"
+		}
+		if f.repeatable {
+			key += "*"
+		}
+		if f.required {
+			key += "!"
+		}
+		item := yaml.MapItem{Key: key, Value: f.usage}
+		if f.persistent {
+			spec.PersistentFlags = append(spec.PersistentFlags, item)
+		} else {
+			spec.Flags = append(spec.Flags, item)
+		}
+
+		name := strings.TrimPrefix(f.long, "--")
+		if len(f.conflicts) > 0 && !seen[name] {
+			group := append([]string{name}, f.conflicts...)
+			for _, n := range group {
+				seen[n] = true
+			}
+			spec.ExclusiveFlags = append(spec.ExclusiveFlags, group)
+		}
+
+		var actions []string
+		switch {
+		case len(f.values) > 0:
+			actions = f.values
+		case f.files && len(f.extensions) > 0:
+			actions = []string{fmt.Sprintf("$files([.%s])", strings.Join(f.extensions, ", ."))}
+		case f.files:
+			actions = []string{"$files"}
+		case f.dirs && len(f.dir) > 0:
+			actions = []string{fmt.Sprintf("$directories ||| $chdir(%s)", f.dir)}
+		case f.dirs:
+			actions = []string{"$directories"}
+		}
+		if len(actions) > 0 {
+			spec.Completion.Flag = append(spec.Completion.Flag, yaml.MapItem{Key: name, Value: actions})
+		}
+	}
+
+	if len(sc.validArgs) > 0 && len(sc.args) > 0 {
+		if sc.args[len(sc.args)-1].variadic {
+			spec.Completion.PositionalAny = sc.validArgs
+		} else {
+			for range sc.args {
+				spec.Completion.Positional = append(spec.Completion.Positional, sc.validArgs)
+			}
+		}
+	}
+	return spec
+}
+
+// GenFigSpec writes a Fig completion spec for cmd and its available
+// subcommands to w as JSON. Completion engines that read Fig specs, like
+// Amazon Q and inshellisense, can use it to complete the command. The number
+// of positional arguments of a command follows from its Args if it is one of
+// the validators of cobra, like NoArgs, ArbitraryArgs or ExactArgs. Commands
+// with custom validators need cobra.ArgCountAnnotation to tell it; without
+// it, they are taken to accept any number of arguments.
+func GenFigSpec(cmd *cobra.Command, w io.Writer) error {
+	b, err := json.MarshalIndent(newSpecCommand(cmd).fig(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// GenCarapaceSpec writes a Carapace spec for cmd and its available
+// subcommands to w as YAML. The number of positional arguments of a command
+// is derived from its Args like for GenFigSpec.
+func GenCarapaceSpec(cmd *cobra.Command, w io.Writer) error {
+	b, err := yaml.Marshal(newSpecCommand(cmd).carapace())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
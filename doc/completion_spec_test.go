@@ -0,0 +1,185 @@
+package doc
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestArgCounts(t *testing.T) {
+	custom := func(*cobra.Command, []string) error { return nil }
+	tests := []struct {
+		cmd      *cobra.Command
+		min, max int
+	}{
+		{&cobra.Command{Use: "c", Run: emptyRun}, 0, -1},
+		{&cobra.Command{Use: "c"}, 0, 0},
+		{&cobra.Command{Use: "c", Args: cobra.NoArgs, Run: emptyRun}, 0, 0},
+		{&cobra.Command{Use: "c", Args: cobra.ExactArgs(2), Run: emptyRun}, 2, 2},
+		{&cobra.Command{Use: "c", Args: cobra.RangeArgs(1, 3), Run: emptyRun}, 1, 3},
+		{&cobra.Command{Use: "c", Args: cobra.MinimumNArgs(1), Run: emptyRun}, 1, -1},
+		{&cobra.Command{Use: "c", Args: custom, Annotations: map[string]string{cobra.ArgCountAnnotation: "2"}, Run: emptyRun}, 2, 2},
+		{&cobra.Command{Use: "c", Args: custom, Run: emptyRun}, 0, -1},
+	}
+	for i, tc := range tests {
+		if min, max := argCounts(tc.cmd); min != tc.min || max != tc.max {
+			t.Errorf("%d: expected %d, %d, got %d, %d", i, tc.min, tc.max, min, max)
+		}
+	}
+}
+
+func TestSpecArgs(t *testing.T) {
+	tests := []struct {
+		cmd      *cobra.Command
+		expected []specArg
+	}{
+		{&cobra.Command{Args: cobra.NoArgs, Run: emptyRun}, nil},
+		{&cobra.Command{Run: emptyRun}, []specArg{{"arg", true, true}}},
+		{&cobra.Command{Use: "c", Args: cobra.NoArgs, Run: emptyRun}, nil},
+		{&cobra.Command{Use: "c", Run: emptyRun}, []specArg{{"arg", true, true}}},
+		{&cobra.Command{Use: "c <src> <dst>", Args: cobra.ExactArgs(2), Run: emptyRun},
+			[]specArg{{"src", false, false}, {"dst", false, false}}},
+		{&cobra.Command{Use: "c [flags] [file]", Args: cobra.RangeArgs(1, 2), Run: emptyRun},
+			[]specArg{{"file", false, false}, {"file", true, false}}},
+		{&cobra.Command{Use: "c files...", Args: cobra.MinimumNArgs(1), Run: emptyRun},
+			[]specArg{{"files", false, true}}},
+		{&cobra.Command{Use: "c <resource> [name...]", Args: cobra.MinimumNArgs(1), Run: emptyRun},
+			[]specArg{{"resource", false, false}, {"name", true, true}}},
+	}
+	for _, tc := range tests {
+		if got := specArgs(tc.cmd); !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q: expected %+v, got %+v", tc.cmd.Use, tc.expected, got)
+		}
+	}
+}
+
+func TestGenFigSpec(t *testing.T) {
+	root := &cobra.Command{Use: "app", Short: "Manage resources"}
+	root.PersistentFlags().String("config", "", "config file")
+	get := &cobra.Command{
+		Use:       "get <resource> [name...]",
+		Aliases:   []string{"g"},
+		ValidArgs: []string{"pod\tA group of containers", "node"},
+		Args:      cobra.MinimumNArgs(1),
+		Run:       emptyRun,
+	}
+	get.Flags().StringP("output", "o", "table", "output format")
+	get.MarkFlagEnum("output", "json", "table")
+	get.Flags().StringSlice("tag", nil, "tags to filter by")
+	get.MarkFlagRequired("tag")
+	get.Flags().Bool("json", false, "print JSON")
+	get.Flags().Bool("yaml", false, "print YAML")
+	get.MarkFlagsMutuallyExclusive("json", "yaml")
+	get.Flags().String("dir", "", "output directory")
+	get.Flags().SetAnnotation("dir", cobra.BashCompSubdirsInDir, []string{"out"})
+	get.Flags().String("secret", "", "")
+	get.Flags().MarkHidden("secret")
+	rm := &cobra.Command{Use: "rm [name]", Args: cobra.RangeArgs(0, 2), Run: emptyRun}
+	root.AddCommand(get, rm, &cobra.Command{Use: "hidden", Hidden: true, Run: emptyRun})
+
+	buf := new(bytes.Buffer)
+	if err := GenFigSpec(root, buf); err != nil {
+		t.Fatal(err)
+	}
+	var spec figSpec
+	if err := json.Unmarshal(buf.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.Name != "app" || len(spec.Subcommands) != 2 {
+		t.Fatalf("Expected app with 2 subcommands, got %v with %d", spec.Name, len(spec.Subcommands))
+	}
+	checkStringContains(t, buf.String(), `"isPersistent": true`)
+
+	getSpec := spec.Subcommands[0]
+	if !reflect.DeepEqual(getSpec.Name, []interface{}{"get", "g"}) {
+		t.Errorf("Expected the get command with its alias, got %v", getSpec.Name)
+	}
+	suggestions := []figSuggestion{{"pod", "A group of containers"}, {"node", ""}}
+	expectedArgs := []figArg{
+		{Name: "resource", Suggestions: suggestions},
+		{Name: "name", IsOptional: true, IsVariadic: true, Suggestions: suggestions},
+	}
+	if !reflect.DeepEqual(getSpec.Args, expectedArgs) {
+		t.Errorf("Expected args %+v, got %+v", expectedArgs, getSpec.Args)
+	}
+
+	options := make(map[string]figOption)
+	for _, opt := range getSpec.Options {
+		if names, ok := opt.Name.([]interface{}); ok {
+			opt.Name = names[0]
+		}
+		options[opt.Name.(string)] = opt
+	}
+	if _, ok := options["--secret"]; ok {
+		t.Error("Expected the hidden flag to be left out")
+	}
+	expectedOptions := []figOption{
+		{Name: "--dir", Description: "output directory", Args: &figArg{Name: "dir", Template: "folders"}},
+		{Name: "--json", Description: "print JSON", ExclusiveOn: []string{"--yaml"}},
+		{Name: "--output", Description: "output format (one of json, table)",
+			Args: &figArg{Name: "output", Suggestions: []figSuggestion{{Name: "json"}, {Name: "table"}}}},
+		{Name: "--tag", Description: "tags to filter by", Args: &figArg{Name: "tag"}, IsRequired: true, IsRepeatable: true},
+	}
+	for _, expected := range expectedOptions {
+		if got := options[expected.Name.(string)]; !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected option %+v, got %+v", expected, got)
+		}
+	}
+
+	if args := spec.Subcommands[1].Args; len(args) != 2 || !args[0].IsOptional || !args[1].IsOptional {
+		t.Errorf("Expected two optional args for rm, got %+v", args)
+	}
+}
+
+func TestGenSpecEmptyUse(t *testing.T) {
+	if err := GenFigSpec(&cobra.Command{}, new(bytes.Buffer)); err != nil {
+		t.Error(err)
+	}
+	if err := GenCarapaceSpec(&cobra.Command{}, new(bytes.Buffer)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGenCarapaceSpec(t *testing.T) {
+	root := &cobra.Command{Use: "app"}
+	root.PersistentFlags().StringP("config", "c", "", "config file")
+	root.MarkPersistentFlagFilename("config", "yaml", "yml")
+	get := &cobra.Command{
+		Use:       "get [name...]",
+		Aliases:   []string{"g"},
+		ValidArgs: []string{"pod\tA group of containers", "node"},
+		Run:       emptyRun,
+	}
+	get.Flags().StringSlice("tag", nil, "tags to filter by")
+	get.MarkFlagRequired("tag")
+	get.Flags().Bool("json", false, "")
+	get.Flags().Bool("yaml", false, "")
+	get.MarkFlagsMutuallyExclusive("json", "yaml")
+	get.Flags().String("dir", "", "")
+	get.Flags().SetAnnotation("dir", cobra.BashCompSubdirsInDir, []string{"out"})
+	get.Flags().String("secret", "", "")
+	get.Flags().MarkHidden("secret")
+	kind := &cobra.Command{Use: "kind", ValidArgs: []string{"pod", "node"}, Args: cobra.ExactValidArgs(1), Run: emptyRun}
+	root.AddCommand(get, kind, &cobra.Command{Use: "hidden", Hidden: true, Run: emptyRun})
+
+	buf := new(bytes.Buffer)
+	if err := GenCarapaceSpec(root, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "persistentflags:\n  -c, --config=: config file\n")
+	checkStringContains(t, output, "    config:\n    - $files([.yaml, .yml])\n")
+	checkStringContains(t, output, "- name: get\n  aliases:\n  - g\n")
+	checkStringContains(t, output, "    --tag=*!: tags to filter by\n")
+	checkStringContains(t, output, "  exclusiveflags:\n  - - json\n    - yaml\n")
+	checkStringContains(t, output, "      - $directories ||| $chdir(out)\n")
+	checkStringContains(t, output, "    positionalany:\n    - \"pod\\tA group of containers\"\n    - node\n")
+	checkStringContains(t, output, "    positional:\n    - - pod\n      - node\n")
+	checkStringOmits(t, output, "secret")
+	checkStringOmits(t, output, "hidden")
+}
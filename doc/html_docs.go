@@ -0,0 +1,377 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// htmlIndexFile is the name of the index page of the site.
+	htmlIndexFile = "index.html"
+	// htmlSearchIndexFile is the name of the search index of the site. It
+	// is a script rather than JSON, as browsers don't let pages opened from
+	// file:// URLs fetch files.
+	htmlSearchIndexFile = "search_index.js"
+	// htmlSearchIndexVar is the variable the search index script assigns
+	// the entries to.
+	htmlSearchIndexVar = "cobraSearchIndex"
+)
+
+// GenHTMLTreeOptions is the options for generating the HTML documentation.
+type GenHTMLTreeOptions struct {
+	// Title is the title of the site. It defaults to the name of the root
+	// command.
+	Title string
+	// Template renders the pages. It must define a "page" template, which is
+	// executed with an *HTMLPage for each page. It defaults to HTMLTemplate.
+	Template *template.Template
+}
+
+// HTMLPage is the data a page of the HTML documentation is rendered with.
+type HTMLPage struct {
+	// Title is the title of the site.
+	Title string
+	// Command is the command the page documents, or nil on the index.
+	Command *HTMLCommand
+	// Commands are all the commands of the tree, in the order of the
+	// sidebar.
+	Commands []*HTMLCommand
+	// Breadcrumbs are the parents of Command, the root first.
+	Breadcrumbs []*HTMLCommand
+	// Sidebar is the navigation of the full tree, with the entry of Command
+	// marked as current.
+	Sidebar []*HTMLNavItem
+	// IndexFile and SearchIndexFile are the file names of the index and of
+	// the search index, a script that assigns the entries of the index to
+	// the global variable cobraSearchIndex.
+	IndexFile, SearchIndexFile string
+	// AutoGenTag is the note that the page was generated, if it isn't
+	// disabled.
+	AutoGenTag string
+}
+
+// HTMLCommand is a command in the HTML documentation.
+type HTMLCommand struct {
+	Name, Path string
+	// File is the file name of the page of the command.
+	File                          string
+	Aliases                       []string
+	Short, Long, UseLine, Example string
+	Deprecated, Stability         string
+	Flags, InheritedFlags         []HTMLFlag
+	Subcommands                   []*HTMLCommand
+}
+
+// HTMLFlag is a flag in the HTML documentation.
+type HTMLFlag struct {
+	Name, Shorthand, Type, Usage string
+	// Default is the default value, empty if it is the zero value.
+	Default string
+	// Anchor is the id of the flag on the page of its command.
+	Anchor string
+}
+
+// HTMLNavItem is an entry of the sidebar.
+type HTMLNavItem struct {
+	Command  *HTMLCommand
+	Current  bool
+	Children []*HTMLNavItem
+}
+
+// htmlSearchEntry is an entry of the search index, a command or a flag.
+type htmlSearchEntry struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url"`
+}
+
+// HTMLTemplate returns the default template of the HTML documentation. Its
+// "page" template lays out a page with the "head", "sidebar", "breadcrumbs",
+// "content" and "footer" templates, any of which can be redefined in the
+// returned template to customize the pages:
+//
+//	tmpl := doc.HTMLTemplate()
+//	template.Must(tmpl.New("footer").Parse(`<footer>ACME Corp.</footer>`))
+//	err := doc.GenHTMLTree(cmd, "/tmp", &doc.GenHTMLTreeOptions{Template: tmpl})
+func HTMLTemplate() *template.Template {
+	return template.Must(template.New("page").Parse(htmlTemplate))
+}
+
+// GenHTMLTree generates an HTML page for this command and all descendants,
+// an index.html page listing them and a search_index.js script for
+// searching them in the browser, in the directory given. The site works
+// when it is opened from the file system as well as when it is served. The opts may be
+// nil. This function may not work correctly if your command names have `_`
+// in them.
+func GenHTMLTree(cmd *cobra.Command, dir string, opts *GenHTMLTreeOptions) error {
+	if opts == nil {
+		opts = &GenHTMLTreeOptions{}
+	}
+	title := opts.Title
+	if len(title) == 0 {
+		title = cmd.Name()
+	}
+	tmpl := opts.Template
+	if tmpl == nil {
+		tmpl = HTMLTemplate()
+	}
+
+	root := newHTMLCommand(cmd)
+	var commands []*HTMLCommand
+	parents := make(map[*HTMLCommand]*HTMLCommand)
+	var walk func(c *HTMLCommand)
+	walk = func(c *HTMLCommand) {
+		commands = append(commands, c)
+		for _, sub := range c.Subcommands {
+			parents[sub] = c
+			walk(sub)
+		}
+	}
+	walk(root)
+
+	autoGenTag := ""
+	if !cmd.DisableAutoGenTag {
+		autoGenTag = "Auto generated by spf13/cobra on " + time.Now().Format("2-Jan-2006")
+	}
+	newPage := func(c *HTMLCommand) *HTMLPage {
+		page := &HTMLPage{
+			Title:           title,
+			Command:         c,
+			Commands:        commands,
+			Sidebar:         htmlSidebar([]*HTMLCommand{root}, c),
+			IndexFile:       htmlIndexFile,
+			SearchIndexFile: htmlSearchIndexFile,
+			AutoGenTag:      autoGenTag,
+		}
+		for p := parents[c]; p != nil; p = parents[p] {
+			page.Breadcrumbs = append([]*HTMLCommand{p}, page.Breadcrumbs...)
+		}
+		return page
+	}
+
+	if err := writeHTMLPage(tmpl, filepath.Join(dir, htmlIndexFile), newPage(nil)); err != nil {
+		return err
+	}
+	var entries []htmlSearchEntry
+	for _, c := range commands {
+		if err := writeHTMLPage(tmpl, filepath.Join(dir, c.File), newPage(c)); err != nil {
+			return err
+		}
+		entries = append(entries, htmlSearchEntry{c.Path, c.Short, c.File})
+		for _, flags := range [][]HTMLFlag{c.Flags, c.InheritedFlags} {
+			for _, f := range flags {
+				entries = append(entries, htmlSearchEntry{c.Path + " --" + f.Name, f.Usage, c.File + "#" + f.Anchor})
+			}
+		}
+	}
+	return writeHTMLSearchIndex(filepath.Join(dir, htmlSearchIndexFile), entries)
+}
+
+// newHTMLCommand returns cmd and its available subcommands, sorted by name,
+// for the HTML documentation.
+func newHTMLCommand(cmd *cobra.Command) *HTMLCommand {
+	cmd.InitDefaultHelpCmd()
+	cmd.InitDefaultHelpFlag()
+
+	hc := &HTMLCommand{
+		Name:           cmd.Name(),
+		Path:           cmd.CommandPath(),
+		File:           strings.Replace(cmd.CommandPath(), " ", "_", -1) + ".html",
+		Short:          cmd.Short,
+		Long:           cmd.Long,
+		Example:        cmd.Example,
+		Flags:          htmlFlags(cmd.UsageFlags(cmd.NonInheritedFlags())),
+		InheritedFlags: htmlFlags(cmd.UsageFlags(cmd.InheritedFlags())),
+	}
+	if len(hc.Long) == 0 {
+		hc.Long = hc.Short
+	}
+	if cmd.Runnable() {
+		hc.UseLine = cmd.UseLine()
+	}
+	if len(cmd.Deprecated) > 0 {
+		hc.Deprecated = cmd.DeprecationMessage()
+	}
+	if cmd.Stability != cobra.StabilityStable {
+		hc.Stability = string(cmd.Stability)
+	}
+	for _, alias := range cmd.Aliases {
+		if !cmd.IsDeprecatedAlias(alias) {
+			hc.Aliases = append(hc.Aliases, alias)
+		}
+	}
+
+	children := cmd.Commands()
+	sort.Sort(byName(children))
+	for _, child := range children {
+		if !child.IsAvailableCommand() || child.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		hc.Subcommands = append(hc.Subcommands, newHTMLCommand(child))
+	}
+	return hc
+}
+
+// htmlFlags returns the flags that are neither hidden nor deprecated.
+func htmlFlags(flags *pflag.FlagSet) []HTMLFlag {
+	var result []HTMLFlag
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 {
+			return
+		}
+		hf := HTMLFlag{
+			Name:   f.Name,
+			Type:   f.Value.Type(),
+			Usage:  f.Usage,
+			Anchor: "flag-" + f.Name,
+		}
+		if len(f.ShorthandDeprecated) == 0 {
+			hf.Shorthand = f.Shorthand
+		}
+		switch f.DefValue {
+		case "", "[]", "false", "0":
+		default:
+			hf.Default = f.DefValue
+		}
+		result = append(result, hf)
+	})
+	return result
+}
+
+// htmlSidebar returns the navigation of commands, with current marked.
+func htmlSidebar(commands []*HTMLCommand, current *HTMLCommand) []*HTMLNavItem {
+	items := make([]*HTMLNavItem, len(commands))
+	for i, c := range commands {
+		items[i] = &HTMLNavItem{
+			Command:  c,
+			Current:  c == current,
+			Children: htmlSidebar(c.Subcommands, current),
+		}
+	}
+	return items
+}
+
+func writeHTMLPage(tmpl *template.Template, filename string, page *HTMLPage) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return tmpl.ExecuteTemplate(f, "page", page)
+}
+
+func writeHTMLSearchIndex(filename string, entries []htmlSearchEntry) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	b, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(f, "var %s = %s;\n", htmlSearchIndexVar, b)
+	return err
+}
+
+const htmlTemplate = `<!DOCTYPE html>
+<html>
+<head>
+{{template "head" .}}
+</head>
+<body>
+<nav class="sidebar">
+{{template "sidebar" .}}
+</nav>
+<main>
+{{template "breadcrumbs" .}}
+{{template "content" .}}
+{{template "footer" .}}
+</main>
+</body>
+</html>
+{{- define "head"}}<meta charset="utf-8">
+<title>{{if .Command}}{{.Command.Path}} - {{end}}{{.Title}}</title>
+<style>
+body { display: flex; margin: 0; font-family: sans-serif; }
+.sidebar { width: 16em; padding: 1em; border-right: 1px solid #ddd; }
+.sidebar ul { list-style: none; padding-left: 1em; }
+.sidebar .current > a { font-weight: bold; }
+main { flex: 1; padding: 1em 2em; }
+pre { background: #f6f6f6; padding: 0.5em; }
+</style>{{end}}
+{{- define "sidebar"}}<a href="{{.IndexFile}}">{{.Title}}</a>
+<input type="search" id="search" placeholder="Search">
+<ul id="search-results"></ul>
+{{template "nav" .Sidebar}}
+<script src="{{.SearchIndexFile}}"></script>
+<script>
+(function() {
+  var input = document.getElementById("search");
+  var results = document.getElementById("search-results");
+  var entries = window.cobraSearchIndex || [];
+  input.addEventListener("input", function() {
+    var query = input.value.toLowerCase();
+    results.innerHTML = "";
+    if (!query) { return; }
+    entries.filter(function(e) {
+      return e.title.toLowerCase().indexOf(query) >= 0 || (e.description || "").toLowerCase().indexOf(query) >= 0;
+    }).slice(0, 20).forEach(function(e) {
+      var a = document.createElement("a");
+      a.href = e.url;
+      a.textContent = e.title;
+      var li = document.createElement("li");
+      li.appendChild(a);
+      results.appendChild(li);
+    });
+  });
+})();
+</script>{{end}}
+{{- define "nav"}}<ul>
+{{range .}}<li{{if .Current}} class="current"{{end}}><a href="{{.Command.File}}">{{.Command.Name}}</a>{{if .Children}}
+{{template "nav" .Children}}{{end}}</li>
+{{end}}</ul>{{end}}
+{{- define "breadcrumbs"}}{{if .Breadcrumbs}}<nav class="breadcrumbs">
+{{range .Breadcrumbs}}<a href="{{.File}}">{{.Name}}</a> &rsaquo;
+{{end}}{{.Command.Name}}
+</nav>{{end}}{{end}}
+{{- define "content"}}{{with .Command}}<h1>{{.Path}}</h1>
+<p>{{.Short}}</p>
+{{if .Deprecated}}<p><strong>Deprecated:</strong> {{.Deprecated}}</p>
+{{end}}{{if .Stability}}<p><strong>Stability:</strong> {{.Stability}}</p>
+{{end}}{{if .Aliases}}<p>Aliases: {{range $i, $a := .Aliases}}{{if $i}}, {{end}}{{$a}}{{end}}</p>
+{{end}}<h2>Synopsis</h2>
+<p>{{.Long}}</p>
+{{if .UseLine}}<pre>{{.UseLine}}</pre>
+{{end}}{{if .Example}}<h2>Examples</h2>
+<pre>{{.Example}}</pre>
+{{end}}{{if .Flags}}<h2>Options</h2>
+{{template "flags" .Flags}}
+{{end}}{{if .InheritedFlags}}<h2>Options inherited from parent commands</h2>
+{{template "flags" .InheritedFlags}}
+{{end}}{{if .Subcommands}}<h2>Commands</h2>
+<dl>
+{{range .Subcommands}}<dt><a href="{{.File}}">{{.Path}}</a></dt><dd>{{.Short}}</dd>
+{{end}}</dl>
+{{end}}{{else}}<h1>{{.Title}}</h1>
+<dl>
+{{range .Commands}}<dt><a href="{{.File}}">{{.Path}}</a></dt><dd>{{.Short}}</dd>
+{{end}}</dl>
+{{end}}{{end}}
+{{- define "flags"}}<dl class="flags">
+{{range .}}<dt id="{{.Anchor}}"><a href="#{{.Anchor}}">{{if .Shorthand}}-{{.Shorthand}}, {{end}}--{{.Name}}</a> <code>{{.Type}}</code>{{if .Default}} (default {{.Default}}){{end}}</dt>
+<dd>{{.Usage}}</dd>
+{{end}}</dl>{{end}}
+{{- define "footer"}}{{if .AutoGenTag}}<footer>{{.AutoGenTag}}</footer>{{end}}{{end}}
+`
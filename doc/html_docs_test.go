@@ -0,0 +1,117 @@
+package doc
+
+import (
+	"encoding/json"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readHTMLTestFile(t *testing.T, dir, name string) string {
+	output, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(output)
+}
+
+func TestGenHTMLTree(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "test-gen-html-tree")
+	if err != nil {
+		t.Fatalf("Failed to create tmpdir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := GenHTMLTree(rootCmd, tmpdir, nil); err != nil {
+		t.Fatalf("GenHTMLTree failed: %v", err)
+	}
+
+	for _, name := range []string{"index.html", "root.html", "root_echo.html", "root_echo_times.html"} {
+		if _, err := os.Stat(filepath.Join(tmpdir, name)); err != nil {
+			t.Errorf("Expected file %q to exist", name)
+		}
+	}
+	for _, name := range []string{"root_echo_deprecated.html", "root_print.html"} {
+		if _, err := os.Stat(filepath.Join(tmpdir, name)); !os.IsNotExist(err) {
+			t.Errorf("Expected no page for the unavailable command %q, got %v", name, err)
+		}
+	}
+
+	index := readHTMLTestFile(t, tmpdir, "index.html")
+	checkStringContains(t, index, `<title>root</title>`)
+	checkStringContains(t, index, `<script src="search_index.js"></script>`)
+	checkStringOmits(t, index, "fetch(")
+	checkStringContains(t, index, `<dt><a href="root_echo_times.html">root echo times</a></dt><dd>Echo anything to the screen more times</dd>`)
+
+	output := readHTMLTestFile(t, tmpdir, "root_echo_times.html")
+	checkStringContains(t, output, `<title>root echo times - root</title>`)
+	checkStringContains(t, output, "<a href=\"root.html\">root</a> &rsaquo;\n<a href=\"root_echo.html\">echo</a> &rsaquo;\ntimes\n")
+	checkStringContains(t, output, `<li class="current"><a href="root_echo_times.html">times</a></li>`)
+	checkStringContains(t, output, `<a href="root_echo_echosub.html">echosub</a>`)
+	checkStringContains(t, output, `<dt id="flag-inttwo"><a href="#flag-inttwo">-j, --inttwo</a> <code>int</code> (default 234)</dt>`)
+	checkStringContains(t, output, `<dt id="flag-booltwo"><a href="#flag-booltwo">-c, --booltwo</a> <code>bool</code></dt>`)
+	checkStringContains(t, output, "Options inherited from parent commands")
+	checkStringContains(t, output, `<a href="#flag-rootflag">`)
+	checkStringContains(t, output, "Auto generated by spf13/cobra")
+	checkStringOmits(t, output, "deprecated")
+}
+
+func TestGenHTMLTreeSearchIndex(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "test-gen-html-tree")
+	if err != nil {
+		t.Fatalf("Failed to create tmpdir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := GenHTMLTree(rootCmd, tmpdir, nil); err != nil {
+		t.Fatalf("GenHTMLTree failed: %v", err)
+	}
+
+	index := readHTMLTestFile(t, tmpdir, "search_index.js")
+	prefix, suffix := "var cobraSearchIndex = ", ";\n"
+	if !strings.HasPrefix(index, prefix) || !strings.HasSuffix(index, suffix) {
+		t.Fatalf("Expected the search index to assign cobraSearchIndex, got %q", index)
+	}
+	var entries []htmlSearchEntry
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(index, prefix), suffix)), &entries); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[htmlSearchEntry]bool)
+	for _, e := range entries {
+		found[e] = true
+	}
+	for _, expected := range []htmlSearchEntry{
+		{"root echo", "Echo anything to the screen", "root_echo.html"},
+		{"root echo --boolone", "help message for flag boolone", "root_echo.html#flag-boolone"},
+		{"root echo times --inttwo", "help message for flag inttwo", "root_echo_times.html#flag-inttwo"},
+	} {
+		if !found[expected] {
+			t.Errorf("Expected the search index to contain %+v, got %+v", expected, entries)
+		}
+	}
+}
+
+func TestGenHTMLTreeCustomTemplate(t *testing.T) {
+	rootCmd.DisableAutoGenTag = true
+	defer func() { rootCmd.DisableAutoGenTag = false }()
+
+	tmpl := HTMLTemplate()
+	template.Must(tmpl.New("footer").Parse(`<footer>ACME docs for {{.Title}}</footer>`))
+	tmpdir, err := ioutil.TempDir("", "test-gen-html-tree")
+	if err != nil {
+		t.Fatalf("Failed to create tmpdir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := GenHTMLTree(rootCmd, tmpdir, &GenHTMLTreeOptions{Title: "Root Reference", Template: tmpl}); err != nil {
+		t.Fatalf("GenHTMLTree failed: %v", err)
+	}
+
+	output := readHTMLTestFile(t, tmpdir, "root_echo.html")
+	checkStringContains(t, output, "<title>root echo - Root Reference</title>")
+	checkStringContains(t, output, "<footer>ACME docs for Root Reference</footer>")
+	checkStringOmits(t, output, "Auto generated")
+}
@@ -155,6 +155,14 @@ func manPreamble(buf *bytes.Buffer, header *GenManHeader, cmd *cobra.Command, da
 	buf.WriteString(fmt.Sprintf("**%s**\n\n", cmd.UseLine()))
 	buf.WriteString("# DESCRIPTION\n")
 	buf.WriteString(description + "\n\n")
+	if len(cmd.Deprecated) > 0 {
+		buf.WriteString("# DEPRECATED\n")
+		buf.WriteString(cmd.DeprecationMessage() + "\n\n")
+	}
+	if cmd.Stability != cobra.StabilityStable {
+		buf.WriteString("# STABILITY\n")
+		buf.WriteString(string(cmd.Stability) + "\n\n")
+	}
 }
 
 func manPrintFlags(buf *bytes.Buffer, flags *pflag.FlagSet) {
@@ -186,13 +194,13 @@ func manPrintFlags(buf *bytes.Buffer, flags *pflag.FlagSet) {
 }
 
 func manPrintOptions(buf *bytes.Buffer, command *cobra.Command) {
-	flags := command.NonInheritedFlags()
+	flags := command.UsageFlags(command.NonInheritedFlags())
 	if flags.HasAvailableFlags() {
 		buf.WriteString("# OPTIONS\n")
 		manPrintFlags(buf, flags)
 		buf.WriteString("\n")
 	}
-	flags = command.InheritedFlags()
+	flags = command.UsageFlags(command.InheritedFlags())
 	if flags.HasAvailableFlags() {
 		buf.WriteString("# OPTIONS INHERITED FROM PARENT COMMANDS\n")
 		manPrintFlags(buf, flags)
@@ -238,6 +246,19 @@ func genMan(cmd *cobra.Command, header *GenManHeader) []byte {
 			seealso := fmt.Sprintf("**%s-%s(%s)**", dashCommandName, c.Name(), header.Section)
 			seealsos = append(seealsos, seealso)
 		}
+		for _, shortcut := range cmd.Shortcuts() {
+			if !shortcut.Target.IsAvailableCommand() {
+				continue
+			}
+			dashTargetPath := strings.Replace(shortcut.Target.CommandPath(), " ", "-", -1)
+			seealso := fmt.Sprintf("**%s(%s)** (shortcut: %s)", dashTargetPath, header.Section, shortcut.CommandPath())
+			seealsos = append(seealsos, seealso)
+		}
+		for _, shortcut := range cmd.ShortcutsTo() {
+			dashOwnerPath := strings.Replace(shortcut.Owner.CommandPath(), " ", "-", -1)
+			seealso := fmt.Sprintf("**%s(%s)** (shortcut: %s)", dashOwnerPath, header.Section, shortcut.CommandPath())
+			seealsos = append(seealsos, seealso)
+		}
 		buf.WriteString(strings.Join(seealsos, ", ") + "\n")
 	}
 	if !cmd.DisableAutoGenTag {
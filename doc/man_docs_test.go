@@ -47,6 +47,21 @@ func TestGenManDoc(t *testing.T) {
 	checkStringContains(t, output, translate("Auto generated"))
 }
 
+func TestGenManDeprecated(t *testing.T) {
+	header := &GenManHeader{
+		Title:   "Project",
+		Section: "2",
+	}
+
+	buf := new(bytes.Buffer)
+	if err := GenMan(deprecatedCmd, header, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, ".SH DEPRECATED\n.PP\nPlease use echo instead; it will be removed in v2.0.0")
+}
+
 func TestGenManNoHiddenParents(t *testing.T) {
 	header := &GenManHeader{
 		Title:   "Project",
@@ -27,7 +27,7 @@ import (
 )
 
 func printOptions(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
-	flags := cmd.NonInheritedFlags()
+	flags := cmd.UsageFlags(cmd.NonInheritedFlags())
 	flags.SetOutput(buf)
 	if flags.HasAvailableFlags() {
 		buf.WriteString("### Options\n\n```\n")
@@ -35,7 +35,7 @@ func printOptions(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
 		buf.WriteString("```\n\n")
 	}
 
-	parentFlags := cmd.InheritedFlags()
+	parentFlags := cmd.UsageFlags(cmd.InheritedFlags())
 	parentFlags.SetOutput(buf)
 	if parentFlags.HasAvailableFlags() {
 		buf.WriteString("### Options inherited from parent commands\n\n```\n")
@@ -66,6 +66,12 @@ func GenMarkdownCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string)
 
 	buf.WriteString("## " + name + "\n\n")
 	buf.WriteString(short + "\n\n")
+	if len(cmd.Deprecated) > 0 {
+		buf.WriteString("**Deprecated:** " + cmd.DeprecationMessage() + "\n\n")
+	}
+	if cmd.Stability != cobra.StabilityStable {
+		buf.WriteString("**Stability:** " + string(cmd.Stability) + "\n\n")
+	}
 	buf.WriteString("### Synopsis\n\n")
 	buf.WriteString(long + "\n\n")
 
@@ -108,6 +114,18 @@ func GenMarkdownCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string)
 			link = strings.Replace(link, " ", "_", -1)
 			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - %s\n", cname, linkHandler(link), child.Short))
 		}
+
+		for _, shortcut := range cmd.Shortcuts() {
+			if !shortcut.Target.IsAvailableCommand() {
+				continue
+			}
+			link := strings.Replace(shortcut.Target.CommandPath(), " ", "_", -1) + ".md"
+			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - Shortcut for %s\n", shortcut.CommandPath(), linkHandler(link), shortcut.Target.CommandPath()))
+		}
+		for _, shortcut := range cmd.ShortcutsTo() {
+			link := strings.Replace(shortcut.Owner.CommandPath(), " ", "_", -1) + ".md"
+			buf.WriteString(fmt.Sprintf("* [%s](%s)\t - Shortcut for %s\n", shortcut.CommandPath(), linkHandler(link), name))
+		}
 		buf.WriteString("\n")
 	}
 	if !cmd.DisableAutoGenTag {
@@ -28,6 +28,37 @@ func TestGenMdDoc(t *testing.T) {
 	checkStringContains(t, output, "Options inherited from parent commands")
 }
 
+func TestGenMdDeprecated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(deprecatedCmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "**Deprecated:** Please use echo instead; it will be removed in v2.0.0")
+}
+
+func TestGenMdShortcuts(t *testing.T) {
+	c := &cobra.Command{Use: "tool", Run: emptyRun}
+	storageCmd := &cobra.Command{Use: "storage", Run: emptyRun}
+	listCmd := &cobra.Command{Use: "list", Run: emptyRun}
+	storageCmd.AddCommand(listCmd)
+	c.AddCommand(storageCmd)
+	c.AddShortcut("ls", "storage list")
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, buf.String(), "* [tool ls](tool_storage_list.md)\t - Shortcut for tool storage list")
+
+	buf.Reset()
+	if err := GenMarkdown(listCmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, buf.String(), "* [tool ls](tool.md)\t - Shortcut for tool storage list")
+}
+
 func TestGenMdNoHiddenParents(t *testing.T) {
 	// We generate on subcommand so we have both subcommands and parents.
 	for _, name := range []string{"rootflag", "strtwo"} {
@@ -81,6 +112,56 @@ func TestGenMdTree(t *testing.T) {
 	}
 }
 
+func TestGenMdTreeStability(t *testing.T) {
+	c := &cobra.Command{Use: "do"}
+	c.AddCommand(
+		&cobra.Command{Use: "alpha", Short: "An alpha command", Stability: cobra.StabilityAlpha, Run: emptyRun},
+		&cobra.Command{Use: "labs", Stability: cobra.StabilityExperimental, Run: emptyRun},
+	)
+	tmpdir, err := ioutil.TempDir("", "test-gen-md-tree")
+	if err != nil {
+		t.Fatalf("Failed to create tmpdir: %v", err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	if err := GenMarkdownTree(c, tmpdir); err != nil {
+		t.Fatalf("GenMarkdownTree failed: %v", err)
+	}
+
+	output, err := ioutil.ReadFile(filepath.Join(tmpdir, "do_alpha.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, string(output), "**Stability:** alpha")
+	if _, err := os.Stat(filepath.Join(tmpdir, "do_labs.md")); !os.IsNotExist(err) {
+		t.Errorf("Expected no docs for the experimental command, got %v", err)
+	}
+
+	os.Setenv(cobra.ExperimentalEnv, "1")
+	defer os.Unsetenv(cobra.ExperimentalEnv)
+	if err := GenMarkdownTree(c, tmpdir); err != nil {
+		t.Fatalf("GenMarkdownTree failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpdir, "do_labs.md")); err != nil {
+		t.Errorf("Expected docs for the enabled experimental command, got %v", err)
+	}
+}
+
+func TestGenMdFlagStability(t *testing.T) {
+	c := &cobra.Command{Use: "do", Run: emptyRun}
+	c.Flags().Bool("fast", false, "go fast")
+	c.MarkFlagStability("fast", cobra.StabilityAlpha)
+	c.Flags().Bool("turbo", false, "go faster")
+	c.MarkFlagStability("turbo", cobra.StabilityExperimental)
+
+	buf := new(bytes.Buffer)
+	if err := GenMarkdown(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, buf.String(), "(alpha) go fast")
+	checkStringOmits(t, buf.String(), "turbo")
+}
+
 func BenchmarkGenMarkdownToFile(b *testing.B) {
 	file, err := ioutil.TempFile("", "")
 	if err != nil {
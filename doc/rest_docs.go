@@ -27,7 +27,7 @@ import (
 )
 
 func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command, name string) error {
-	flags := cmd.NonInheritedFlags()
+	flags := cmd.UsageFlags(cmd.NonInheritedFlags())
 	flags.SetOutput(buf)
 	if flags.HasAvailableFlags() {
 		buf.WriteString("Options\n")
@@ -36,7 +36,7 @@ func printOptionsReST(buf *bytes.Buffer, cmd *cobra.Command, name string) error
 		buf.WriteString("\n")
 	}
 
-	parentFlags := cmd.InheritedFlags()
+	parentFlags := cmd.UsageFlags(cmd.InheritedFlags())
 	parentFlags.SetOutput(buf)
 	if parentFlags.HasAvailableFlags() {
 		buf.WriteString("Options inherited from parent commands\n")
@@ -76,6 +76,12 @@ func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, str
 	buf.WriteString(name + "\n")
 	buf.WriteString(strings.Repeat("-", len(name)) + "\n\n")
 	buf.WriteString(short + "\n\n")
+	if len(cmd.Deprecated) > 0 {
+		buf.WriteString(".. warning::\n\n   Deprecated: " + cmd.DeprecationMessage() + "\n\n")
+	}
+	if cmd.Stability != cobra.StabilityStable {
+		buf.WriteString(".. note::\n\n   Stability: " + string(cmd.Stability) + "\n\n")
+	}
 	buf.WriteString("Synopsis\n")
 	buf.WriteString("~~~~~~~~\n\n")
 	buf.WriteString("\n" + long + "\n\n")
@@ -119,6 +125,18 @@ func GenReSTCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string, str
 			ref = strings.Replace(cname, " ", "_", -1)
 			buf.WriteString(fmt.Sprintf("* %s \t - %s\n", linkHandler(cname, ref), child.Short))
 		}
+
+		for _, shortcut := range cmd.Shortcuts() {
+			if !shortcut.Target.IsAvailableCommand() {
+				continue
+			}
+			ref = strings.Replace(shortcut.Target.CommandPath(), " ", "_", -1)
+			buf.WriteString(fmt.Sprintf("* %s \t - Shortcut for %s\n", linkHandler(shortcut.CommandPath(), ref), shortcut.Target.CommandPath()))
+		}
+		for _, shortcut := range cmd.ShortcutsTo() {
+			ref = strings.Replace(shortcut.Owner.CommandPath(), " ", "_", -1)
+			buf.WriteString(fmt.Sprintf("* %s \t - Shortcut for %s\n", linkHandler(shortcut.CommandPath(), ref), name))
+		}
 		buf.WriteString("\n")
 	}
 	if !cmd.DisableAutoGenTag {
@@ -27,6 +27,16 @@ func TestGenRSTDoc(t *testing.T) {
 	checkStringOmits(t, output, deprecatedCmd.Short)
 }
 
+func TestGenRSTDeprecated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := GenReST(deprecatedCmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, ".. warning::\n\n   Deprecated: Please use echo instead; it will be removed in v2.0.0")
+}
+
 func TestGenRSTNoHiddenParents(t *testing.T) {
 	// We generate on a subcommand so we have both subcommands and parents
 	for _, name := range []string{"rootflag", "strtwo"} {
@@ -37,6 +37,8 @@ type cmdDoc struct {
 	Name             string
 	Synopsis         string      `yaml:",omitempty"`
 	Description      string      `yaml:",omitempty"`
+	Deprecated       string      `yaml:",omitempty"`
+	Stability        string      `yaml:",omitempty"`
 	Options          []cmdOption `yaml:",omitempty"`
 	InheritedOptions []cmdOption `yaml:"inherited_options,omitempty"`
 	Example          string      `yaml:",omitempty"`
@@ -97,16 +99,20 @@ func GenYamlCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string) str
 
 	yamlDoc.Synopsis = forceMultiLine(cmd.Short)
 	yamlDoc.Description = forceMultiLine(cmd.Long)
+	if len(cmd.Deprecated) > 0 {
+		yamlDoc.Deprecated = cmd.DeprecationMessage()
+	}
+	yamlDoc.Stability = string(cmd.Stability)
 
 	if len(cmd.Example) > 0 {
 		yamlDoc.Example = cmd.Example
 	}
 
-	flags := cmd.NonInheritedFlags()
+	flags := cmd.UsageFlags(cmd.NonInheritedFlags())
 	if flags.HasFlags() {
 		yamlDoc.Options = genFlagResult(flags)
 	}
-	flags = cmd.InheritedFlags()
+	flags = cmd.UsageFlags(cmd.InheritedFlags())
 	if flags.HasFlags() {
 		yamlDoc.InheritedOptions = genFlagResult(flags)
 	}
@@ -125,6 +131,15 @@ func GenYamlCustom(cmd *cobra.Command, w io.Writer, linkHandler func(string) str
 			}
 			result = append(result, child.Name()+" - "+child.Short)
 		}
+		for _, shortcut := range cmd.Shortcuts() {
+			if !shortcut.Target.IsAvailableCommand() {
+				continue
+			}
+			result = append(result, shortcut.Name+" - Shortcut for "+shortcut.Target.CommandPath())
+		}
+		for _, shortcut := range cmd.ShortcutsTo() {
+			result = append(result, shortcut.CommandPath()+" - Shortcut for "+cmd.CommandPath())
+		}
 		yamlDoc.SeeAlso = result
 	}
 
@@ -26,6 +26,26 @@ func TestGenYamlDoc(t *testing.T) {
 	checkStringContains(t, output, echoSubCmd.Short)
 }
 
+func TestGenYamlDeprecated(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := GenYaml(deprecatedCmd, buf); err != nil {
+		t.Fatal(err)
+	}
+	output := buf.String()
+
+	checkStringContains(t, output, "deprecated: Please use echo instead; it will be removed in v2.0.0")
+}
+
+func TestGenYamlStability(t *testing.T) {
+	c := &cobra.Command{Use: "do", Stability: cobra.StabilityBeta, Run: emptyRun}
+
+	buf := new(bytes.Buffer)
+	if err := GenYaml(c, buf); err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, buf.String(), "stability: beta")
+}
+
 func TestGenYamlNoTag(t *testing.T) {
 	rootCmd.DisableAutoGenTag = true
 	defer func() { rootCmd.DisableAutoGenTag = false }()
@@ -0,0 +1,104 @@
+package cobra
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagEnumAnnotation is the flag annotation holding the values set by MarkFlagEnum.
+const FlagEnumAnnotation = "cobra_annotation_enum_values"
+
+// MarkFlagEnum restricts the values of a flag, if it exists, to values. The
+// values are appended to the usage of the flag, so they are listed in help
+// and docs, and the completion generators offer them. Setting the flag to
+// any other value is a flag error, which suggests the closest values.
+//
+// For flags with a slice type, each element must be one of values.
+func MarkFlagEnum(flags *pflag.FlagSet, name string, values ...string) error {
+	f := flags.Lookup(name)
+	if f == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("no values given for flag %q", name)
+	}
+	if err := flags.SetAnnotation(name, FlagEnumAnnotation, values); err != nil {
+		return err
+	}
+	f.Usage = strings.TrimSpace(fmt.Sprintf("%s (one of %s)", f.Usage, strings.Join(values, ", ")))
+	return nil
+}
+
+// MarkFlagEnum restricts the values of a local flag of c.
+func (c *Command) MarkFlagEnum(name string, values ...string) error {
+	return MarkFlagEnum(c.Flags(), name, values...)
+}
+
+// MarkPersistentFlagEnum restricts the values of a persistent flag of c.
+func (c *Command) MarkPersistentFlagEnum(name string, values ...string) error {
+	return MarkFlagEnum(c.PersistentFlags(), name, values...)
+}
+
+// flagEnumValues returns the values set by MarkFlagEnum for f, or nil.
+func flagEnumValues(f *pflag.Flag) []string {
+	return f.Annotations[FlagEnumAnnotation]
+}
+
+// checkFlagEnums returns an error for the first flag of c that was set to
+// a value not allowed by MarkFlagEnum.
+func (c *Command) checkFlagEnums() error {
+	var err error
+	c.Flags().Visit(func(f *pflag.Flag) {
+		values := flagEnumValues(f)
+		if err != nil || len(values) == 0 {
+			return
+		}
+		set := []string{f.Value.String()}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			set = sv.GetSlice()
+		}
+		for _, v := range set {
+			if !stringInSlice(v, values) {
+				err = c.flagEnumError(f, v, values)
+				return
+			}
+		}
+	})
+	return err
+}
+
+// flagEnumError returns the error for setting f to value, which is not one of
+// values, in the format pflag uses for invalid flag values.
+func (c *Command) flagEnumError(f *pflag.Flag, value string, values []string) error {
+	msg := fmt.Sprintf("invalid argument %q for %q flag: must be one of %s",
+		value, flagDisplayName(f), strings.Join(values, ", "))
+
+	if !c.DisableSuggestions {
+		if suggestions := c.enumSuggestionsFor(value, values); len(suggestions) > 0 {
+			msg += "\n\nDid you mean this?\n"
+			for _, s := range suggestions {
+				msg += fmt.Sprintf("\t%v\n", s)
+			}
+		}
+	}
+	return fmt.Errorf("%s", msg)
+}
+
+// enumSuggestionsFor returns the values that are close to typed, using the
+// same rules as SuggestionsFor uses for command names.
+func (c *Command) enumSuggestionsFor(typed string, values []string) []string {
+	if c.SuggestionsMinimumDistance <= 0 {
+		c.SuggestionsMinimumDistance = 2
+	}
+	var suggestions []string
+	for _, v := range values {
+		suggestByLevenshtein := ld(typed, v, true) <= c.SuggestionsMinimumDistance
+		suggestByPrefix := len(typed) > 0 && strings.HasPrefix(strings.ToLower(v), strings.ToLower(typed))
+		if suggestByLevenshtein || suggestByPrefix {
+			suggestions = append(suggestions, v)
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,117 @@
+package cobra
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestEnumFlagValid(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format")
+	rootCmd.MarkPersistentFlagEnum("output", "json", "yaml", "table")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().StringSlice("columns", nil, "")
+	childCmd.MarkFlagEnum("columns", "name", "size", "age")
+	rootCmd.AddCommand(childCmd)
+
+	c, _, err := executeCommandC(rootCmd, "child", "-o", "json", "--columns", "name,age")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if output, _ := c.Flags().GetString("output"); output != "json" {
+		t.Errorf("Expected output %q, got %q", "json", output)
+	}
+}
+
+func TestEnumFlagInvalid(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format")
+	rootCmd.MarkPersistentFlagEnum("output", "json", "yaml", "table")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().StringSlice("columns", nil, "")
+	childCmd.MarkFlagEnum("columns", "name", "size", "age")
+	rootCmd.AddCommand(childCmd)
+
+	_, err := executeCommand(rootCmd, "--output", "jsno")
+	expected := "invalid argument \"jsno\" for \"-o, --output\" flag: must be one of json, yaml, table\n\nDid you mean this?\n\tjson\n"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "child", "--columns", "name,colour")
+	expected = "invalid argument \"colour\" for \"--columns\" flag: must be one of name, size, age"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestEnumFlagUsage(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().String("output", "table", "output format")
+	rootCmd.MarkFlagEnum("output", "json", "yaml", "table")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, `output format (one of json, yaml, table) (default "table")`)
+}
+
+func TestMarkFlagEnumMissingFlag(t *testing.T) {
+	c := &Command{Use: "c"}
+	if err := c.MarkFlagEnum("missing", "a"); err == nil {
+		t.Error("Expected an error for a missing flag")
+	}
+}
+
+func TestEnumFlagCompletions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format")
+	rootCmd.MarkPersistentFlagEnum("output", "json", "yaml", "table")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().StringSlice("columns", nil, "")
+	childCmd.MarkFlagEnum("columns", "name", "size", "age")
+	rootCmd.AddCommand(childCmd)
+
+	tests := []struct {
+		args       []string
+		toComplete string
+		expected   []string
+	}{
+		{[]string{"-o"}, "", []string{"json", "table", "yaml"}},
+		{[]string{"child", "--output"}, "y", []string{"yaml"}},
+		{nil, "--output=t", []string{"--output=table"}},
+		{[]string{"child", "--columns"}, "", []string{"age", "name", "size"}},
+	}
+	for _, tc := range tests {
+		got := rootCmd.completions(tc.args, tc.toComplete)
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q %q: expected %q, got %q", tc.args, tc.toComplete, tc.expected, got)
+		}
+	}
+}
+
+func TestEnumFlagShellCompletions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("output", "o", "table", "output format")
+	rootCmd.MarkPersistentFlagEnum("output", "json", "yaml", "table")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().StringSlice("columns", nil, "")
+	childCmd.MarkFlagEnum("columns", "name", "size", "age")
+	rootCmd.AddCommand(childCmd)
+
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletion(buf)
+	output := buf.String()
+	check(t, output, `flags_with_completion+=("--columns=")`)
+	check(t, output, `flags_completion+=("__root_handle_enum_flag name size age")`)
+
+	buf.Reset()
+	rootCmd.GenZshCompletion(buf)
+	output = buf.String()
+	checkStringContains(t, output, `'(-o --output)--output=[output format (one of json, yaml, table)]:output:(json yaml table)' \`)
+	checkStringContains(t, output, `'(-o --output)-o+[output format (one of json, yaml, table)]:output:(json yaml table)' \`)
+	checkStringContains(t, output, `'*--columns=[(one of name, size, age)]:columns:(name size age)' \`)
+}
@@ -0,0 +1,149 @@
+package cobra
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// EventType identifies the kind of an Event.
+type EventType string
+
+const (
+	// EventCommandStarted is sent when the command to execute was found,
+	// before its flags are parsed.
+	EventCommandStarted EventType = "command_started"
+	// EventCommandFinished is sent when an execution is done, whether it
+	// succeeded or not.
+	EventCommandFinished EventType = "command_finished"
+)
+
+// The types of errors reported in Event.ErrorType.
+const (
+	// ErrorTypeCommand is the type of errors finding the command to execute,
+	// e.g. unknown commands.
+	ErrorTypeCommand = "command"
+	// ErrorTypeFlag is the type of errors parsing or validating flags.
+	ErrorTypeFlag = "flag"
+	// ErrorTypeArgs is the type of errors validating positional arguments.
+	ErrorTypeArgs = "args"
+	// ErrorTypeRun is the type of errors returned by hooks and Run functions.
+	ErrorTypeRun = "run"
+	// ErrorTypeExperimental is the type of errors running experimental
+	// commands or passing experimental flags while experimental features are
+	// disabled.
+	ErrorTypeExperimental = "experimental"
+)
+
+// Event describes the usage of a command, for local usage analytics. Events
+// hold the names of the flags that were set, but never their values unless
+// they were opted in to with SetEventFlagValues, nor positional arguments.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+	// Command is the path of the command, e.g. "app server start".
+	Command string `json:"command"`
+	// Flags are the sorted names of the flags that were set. They are only
+	// known when the command is finished.
+	Flags []string `json:"flags,omitempty"`
+	// FlagValues maps the names in Flags to their values, if enabled.
+	FlagValues map[string]string `json:"flagValues,omitempty"`
+	// Duration is the time the execution took, in nanoseconds in JSON.
+	Duration time.Duration `json:"duration,omitempty"`
+	// ExitCode is the exit code for the error of the execution, see ExitCode.
+	ExitCode int `json:"exitCode"`
+	// ErrorType is one of the ErrorType constants if the execution failed.
+	ErrorType string `json:"errorType,omitempty"`
+}
+
+// EventSink receives the usage events of a command tree. Sinks must not
+// block for long, and they must be safe for concurrent use if the tree is,
+// e.g. by executing clones of it. Errors of a sink are its own to handle;
+// they never affect the execution of a command.
+type EventSink interface {
+	Event(e Event)
+}
+
+// SetEventSink sets the sink that receives usage events for the executions of
+// the command tree of c. Events are only sent to sinks that were set; cobra
+// never collects or sends usage data by itself.
+func (c *Command) SetEventSink(sink EventSink) {
+	c.Root().eventSink = sink
+}
+
+// SetEventFlagValues includes the values of the flags that were set in the
+// events of the command tree of c. Flag values can contain paths, names or
+// secrets, so this should only be enabled for trees whose flags are known to
+// be safe to record.
+func (c *Command) SetEventFlagValues(enabled bool) {
+	c.Root().eventFlagValues = enabled
+}
+
+// sendEvent sends e to the event sink of the tree of c, if it has one.
+func (c *Command) sendEvent(e Event) {
+	root := c.Root()
+	if root.eventSink == nil {
+		return
+	}
+	e.Time = time.Now()
+	if e.Type == EventCommandFinished {
+		c.Flags().Visit(func(f *flag.Flag) {
+			e.Flags = append(e.Flags, f.Name)
+			if root.eventFlagValues {
+				if e.FlagValues == nil {
+					e.FlagValues = make(map[string]string)
+				}
+				e.FlagValues[f.Name] = f.Value.String()
+			}
+		})
+		sort.Strings(e.Flags)
+	}
+	root.eventSink.Event(e)
+}
+
+// JSONLinesSink is an EventSink that writes each event as a line of JSON.
+type JSONLinesSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesSink returns a sink that writes events to w.
+func NewJSONLinesSink(w io.Writer) *JSONLinesSink {
+	return &JSONLinesSink{w: w}
+}
+
+// OpenJSONLinesSink returns a sink that appends events to the file named
+// path, which is created if it doesn't exist. The file should be closed with
+// Close when the program is done.
+func OpenJSONLinesSink(path string) (*JSONLinesSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONLinesSink{w: f, closer: f}, nil
+}
+
+// Event writes e as a line of JSON. Write errors are ignored.
+func (s *JSONLinesSink) Event(e Event) {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Write(append(line, '\n'))
+}
+
+// Close closes the file of a sink opened with OpenJSONLinesSink.
+func (s *JSONLinesSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
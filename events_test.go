@@ -0,0 +1,157 @@
+package cobra
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memorySink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *memorySink) Event(e Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+}
+
+func TestEvents(t *testing.T) {
+	sink := &memorySink{}
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().String("token", "", "")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().Bool("force", false, "")
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetEventSink(sink)
+
+	if _, err := executeCommand(rootCmd, "child", "--token", "secret", "--force"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(sink.events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(sink.events))
+	}
+	started, finished := sink.events[0], sink.events[1]
+	if started.Type != EventCommandStarted || started.Command != "root child" {
+		t.Errorf("Unexpected started event: %+v", started)
+	}
+	if finished.Type != EventCommandFinished || finished.Command != "root child" || finished.ExitCode != 0 || finished.ErrorType != "" {
+		t.Errorf("Unexpected finished event: %+v", finished)
+	}
+	if !reflect.DeepEqual(finished.Flags, []string{"force", "token"}) {
+		t.Errorf("Expected the names of the flags, got %q", finished.Flags)
+	}
+	if finished.FlagValues != nil {
+		t.Errorf("Expected no flag values by default, got %v", finished.FlagValues)
+	}
+	if finished.Duration <= 0 || finished.Time.IsZero() {
+		t.Errorf("Expected a duration and time, got %+v", finished)
+	}
+}
+
+func TestEventsFlagValues(t *testing.T) {
+	sink := &memorySink{}
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().String("token", "", "")
+	rootCmd.AddCommand(&Command{Use: "child", Run: emptyRun})
+	rootCmd.SetEventSink(sink)
+	rootCmd.SetEventFlagValues(true)
+
+	if _, err := executeCommand(rootCmd, "child", "--token", "abc"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := map[string]string{"token": "abc"}
+	if got := sink.events[1].FlagValues; !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected flag values %v, got %v", expected, got)
+	}
+}
+
+func TestEventsErrorTypes(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"unknown"}, ErrorTypeCommand},
+		{[]string{"child", "--unknown"}, ErrorTypeFlag},
+		{[]string{"child", "arg"}, ErrorTypeArgs},
+		{[]string{"fail"}, ErrorTypeRun},
+		{[]string{"labs"}, ErrorTypeExperimental},
+	}
+	for _, tc := range tests {
+		sink := &memorySink{}
+		rootCmd := &Command{Use: "root", Run: emptyRun, SilenceErrors: true, SilenceUsage: true}
+		rootCmd.AddCommand(
+			&Command{Use: "child", Args: NoArgs, Run: emptyRun},
+			&Command{Use: "fail", RunE: func(*Command, []string) error { return errors.New("boom") }},
+			&Command{Use: "labs", Stability: StabilityExperimental, Run: emptyRun},
+		)
+		rootCmd.SetEventSink(sink)
+
+		if _, err := executeCommand(rootCmd, tc.args...); err == nil {
+			t.Errorf("%q: expected an error", tc.args)
+			continue
+		}
+		finished := sink.events[len(sink.events)-1]
+		if finished.ErrorType != tc.expected || finished.ExitCode != 1 {
+			t.Errorf("%q: expected error type %q and exit code 1, got %q and %d",
+				tc.args, tc.expected, finished.ErrorType, finished.ExitCode)
+		}
+	}
+}
+
+func TestJSONLinesSink(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "cobra-events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	path := filepath.Join(tmpdir, "events.jsonl")
+
+	for i := 0; i < 2; i++ {
+		sink, err := OpenJSONLinesSink(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rootCmd := &Command{Use: "root", Run: emptyRun}
+		childCmd := &Command{Use: "child", Run: emptyRun}
+		childCmd.Flags().Bool("force", false, "")
+		rootCmd.AddCommand(childCmd)
+		rootCmd.SetEventSink(sink)
+		if _, err := executeCommand(rootCmd, "child", "--force"); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 lines, got %d:\n%s", len(lines), data)
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(lines[3]), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != EventCommandFinished || e.Command != "root child" || !reflect.DeepEqual(e.Flags, []string{"force"}) {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+
+	buf := new(bytes.Buffer)
+	NewJSONLinesSink(buf).Event(Event{Type: EventCommandStarted, Command: "root"})
+	checkStringContains(t, buf.String(), `"type":"command_started"`)
+	checkStringContains(t, buf.String(), `"command":"root"`)
+	checkStringOmits(t, buf.String(), "flags")
+}
@@ -0,0 +1,92 @@
+package cobra
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// MarkFlagsMutuallyExclusive marks the flags names of c as mutually
+// exclusive: executing c with more than one of them set is a flag error, and
+// the completions of c don't offer the others once one of them is on the
+// command line. The flags can be local or persistent flags of c, or
+// persistent flags of its parents that were already added. The group only
+// applies to c, not to its parents or subcommands.
+func (c *Command) MarkFlagsMutuallyExclusive(names ...string) error {
+	if len(names) < 2 {
+		return fmt.Errorf("a group of mutually exclusive flags needs at least two flags")
+	}
+	c.mergePersistentFlags()
+	for _, name := range names {
+		if c.Flags().Lookup(name) == nil {
+			return fmt.Errorf("no such flag -%v", name)
+		}
+	}
+	c.flagGroups = append(c.flagGroups, append([]string(nil), names...))
+	return nil
+}
+
+// MutuallyExclusiveFlagGroups returns the names of the flags of each group
+// marked with MarkFlagsMutuallyExclusive on c.
+func (c *Command) MutuallyExclusiveFlagGroups() [][]string {
+	groups := make([][]string, len(c.flagGroups))
+	for i, group := range c.flagGroups {
+		groups[i] = append([]string(nil), group...)
+	}
+	return groups
+}
+
+// validateFlagGroups returns an error if more than one flag of a group of
+// mutually exclusive flags of c was set.
+func (c *Command) validateFlagGroups() error {
+	for _, group := range c.flagGroups {
+		var set []string
+		for _, name := range group {
+			if f := c.Flags().Lookup(name); f != nil && f.Changed {
+				set = append(set, name)
+			}
+		}
+		if len(set) > 1 {
+			return fmt.Errorf("if any flags in the group [%s] are set none of the others can be; [%s] were all set",
+				strings.Join(group, " "), strings.Join(set, " "))
+		}
+	}
+	return nil
+}
+
+// flagConflicts returns the names of the flags that are mutually exclusive
+// with the flag name in c.
+func (c *Command) flagConflicts(name string) []string {
+	var names []string
+	for _, group := range c.flagGroups {
+		for _, n := range group {
+			if n == name {
+				for _, other := range group {
+					if other != name {
+						names = append(names, other)
+					}
+				}
+				break
+			}
+		}
+	}
+	return names
+}
+
+// FlagRequired reports whether f was marked with MarkFlagRequired.
+func FlagRequired(f *pflag.Flag) bool {
+	required, found := f.Annotations[BashCompOneRequiredFlag]
+	return found && len(required) > 0 && required[0] == "true"
+}
+
+// FlagRepeatable reports whether f can usefully be given more than once on
+// a command line, like slices, arrays, maps and counts, so completions
+// offer it again after it was used.
+func FlagRepeatable(f *pflag.Flag) bool {
+	if _, ok := f.Value.(pflag.SliceValue); ok {
+		return true
+	}
+	t := f.Value.Type()
+	return t == "count" || strings.HasSuffix(t, "Slice") || strings.HasSuffix(t, "Array") || strings.HasPrefix(t, "stringTo")
+}
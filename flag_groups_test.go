@@ -0,0 +1,114 @@
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newFlagGroupsTestRoot returns a root with a get command, which has a
+// required flag, a repeatable flag and two mutually exclusive flags. The
+// completion tests of every shell share it.
+func newFlagGroupsTestRoot() *Command {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	getCmd := &Command{Use: "get", Run: emptyRun}
+	getCmd.Flags().StringP("output", "o", "", "output file")
+	getCmd.MarkFlagRequired("output")
+	getCmd.Flags().StringSliceP("tag", "t", nil, "tags to get")
+	getCmd.Flags().BoolP("verbose", "v", false, "verbose output")
+	getCmd.Flags().Bool("json", false, "print JSON")
+	getCmd.Flags().Bool("yaml", false, "print YAML")
+	getCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	rootCmd.AddCommand(getCmd)
+	return rootCmd
+}
+
+func TestMarkFlagsMutuallyExclusive(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	getCmd := &Command{Use: "get", Run: emptyRun}
+	getCmd.Flags().StringP("output", "o", "", "")
+	getCmd.MarkFlagRequired("output")
+	getCmd.Flags().Bool("json", false, "")
+	getCmd.Flags().Bool("yaml", false, "")
+	getCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+	rootCmd.AddCommand(getCmd)
+
+	if _, err := executeCommand(rootCmd, "get", "-o", "out", "--json"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err := executeCommand(rootCmd, "get", "-o", "out", "--json", "--yaml")
+	expected := "if any flags in the group [json yaml] are set none of the others can be; [json yaml] were all set"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestMarkFlagsMutuallyExclusiveErrors(t *testing.T) {
+	c := &Command{Use: "c", Run: emptyRun}
+	c.Flags().Bool("json", false, "")
+
+	if err := c.MarkFlagsMutuallyExclusive("json"); err == nil {
+		t.Error("Expected an error for a group of one flag")
+	}
+	if err := c.MarkFlagsMutuallyExclusive("json", "missing"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Errorf("Expected an error for a missing flag, got %v", err)
+	}
+}
+
+func TestMarkFlagsMutuallyExclusivePersistent(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.PersistentFlags().Bool("quiet", false, "")
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().Bool("verbose", false, "")
+	rootCmd.AddCommand(childCmd)
+	if err := childCmd.MarkFlagsMutuallyExclusive("quiet", "verbose"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err := executeCommand(rootCmd, "child", "--quiet", "--verbose")
+	if err == nil || !strings.Contains(err.Error(), "[quiet verbose] were all set") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestMarkFlagsMutuallyExclusiveOnlyAppliesToCommand(t *testing.T) {
+	rootCmd := &Command{Use: "app", Run: emptyRun}
+	rootCmd.PersistentFlags().Bool("global", false, "")
+	c1 := &Command{Use: "c1", Run: emptyRun}
+	c1.Flags().Bool("a", false, "")
+	c2 := &Command{Use: "c2", Run: emptyRun}
+	c2.Flags().Bool("a", false, "")
+	rootCmd.AddCommand(c1, c2)
+	if err := c1.MarkFlagsMutuallyExclusive("global", "a"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := executeCommand(rootCmd, "c2", "--global", "--a"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if got := rootCmd.completions([]string{"c2", "--global"}, "--"); !reflect.DeepEqual(got, []string{"--a", "--help"}) {
+		t.Errorf("Expected the completions of c2 to offer --a, got %q", got)
+	}
+
+	rootCmd.ResetFlagsState()
+	if _, err := executeCommand(rootCmd, "c1", "--global", "--a"); err == nil {
+		t.Error("Expected an error for c1")
+	}
+}
+
+func TestFlagRepeatable(t *testing.T) {
+	c := &Command{Use: "c"}
+	c.Flags().String("string", "", "")
+	c.Flags().StringSlice("slice", nil, "")
+	c.Flags().StringArray("array", nil, "")
+	c.Flags().CountP("count", "v", "")
+	c.Flags().StringToString("map", nil, "")
+
+	for name, expected := range map[string]bool{"string": false, "slice": true, "array": true, "count": true, "map": true} {
+		if got := FlagRepeatable(c.Flags().Lookup(name)); got != expected {
+			t.Errorf("%s: expected repeatable to be %v, got %v", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package cobra
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// FlagValidator checks the value of a flag that was set on the command line.
+// The error it returns describes what is wrong with the value, e.g. "must be
+// between 1 and 65535", and is reported as a flag error.
+type FlagValidator func(f *pflag.Flag) error
+
+// AddFlagValidator adds fn to the validators of the flag name. The validators
+// of the flags set on the command line are run after the flags are parsed and
+// before any hooks or the Run functions of the command. All their errors are
+// reported together, as a single error passed to the FlagErrorFunc.
+//
+// Validators added to a command also apply to its persistent flag name in
+// all subcommands.
+func (c *Command) AddFlagValidator(name string, fn FlagValidator) {
+	if c.flagValidators == nil {
+		c.flagValidators = make(map[string][]FlagValidator)
+	}
+	c.flagValidators[name] = append(c.flagValidators[name], fn)
+}
+
+// validateFlags runs the validators of c and its parents for the flags that
+// were set, and returns an error listing all the invalid values.
+func (c *Command) validateFlags() error {
+	var messages []string
+	c.Flags().Visit(func(f *pflag.Flag) {
+		for p := c; p != nil; p = p.Parent() {
+			if p != c && p.PersistentFlags().Lookup(f.Name) != f {
+				// The validators of p are for its own flag of that name.
+				continue
+			}
+			for _, fn := range p.flagValidators[f.Name] {
+				if err := fn(f); err != nil {
+					messages = append(messages, fmt.Sprintf("invalid argument %q for %q flag: %v",
+						f.Value.String(), flagDisplayName(f), err))
+				}
+			}
+		}
+	})
+	if len(messages) > 0 {
+		return fmt.Errorf("%s", strings.Join(messages, "\n"))
+	}
+	return nil
+}
+
+// flagDisplayName returns the name of f the way pflag shows it in errors,
+// e.g. "-o, --output".
+func flagDisplayName(f *pflag.Flag) string {
+	if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+		return fmt.Sprintf("-%s, --%s", f.Shorthand, f.Name)
+	}
+	return "--" + f.Name
+}
+
+// eachFlagValue calls fn for the value of f, or for each element of the value
+// if f has a slice type. The errors for elements name the element.
+func eachFlagValue(f *pflag.Flag, fn func(value string) error) error {
+	sv, ok := f.Value.(pflag.SliceValue)
+	if !ok {
+		return fn(f.Value.String())
+	}
+	for _, v := range sv.GetSlice() {
+		if err := fn(v); err != nil {
+			return fmt.Errorf("%q %v", v, err)
+		}
+	}
+	return nil
+}
+
+// FlagInRange returns a validator that requires the value of a flag to be a
+// number between min and max, inclusive.
+func FlagInRange(min, max float64) FlagValidator {
+	return func(f *pflag.Flag) error {
+		return eachFlagValue(f, func(value string) error {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil || n < min || n > max {
+				return fmt.Errorf("must be between %v and %v", min, max)
+			}
+			return nil
+		})
+	}
+}
+
+// FlagMatches returns a validator that requires the value of a flag to match
+// the regular expression pattern. It panics if pattern can't be compiled.
+func FlagMatches(pattern string) FlagValidator {
+	re := regexp.MustCompile(pattern)
+	return func(f *pflag.Flag) error {
+		return eachFlagValue(f, func(value string) error {
+			if !re.MatchString(value) {
+				return fmt.Errorf("must match %s", pattern)
+			}
+			return nil
+		})
+	}
+}
+
+// FlagURL requires the value of a flag to be an absolute URL.
+func FlagURL(f *pflag.Flag) error {
+	return eachFlagValue(f, func(value string) error {
+		u, err := url.Parse(value)
+		if err != nil || len(u.Scheme) == 0 || len(u.Host) == 0 {
+			return fmt.Errorf("must be a URL with a scheme and a host")
+		}
+		return nil
+	})
+}
+
+// FlagFileExists requires the value of a flag to be the path of an existing
+// file that is not a directory.
+func FlagFileExists(f *pflag.Flag) error {
+	return eachFlagValue(f, func(value string) error {
+		info, err := os.Stat(value)
+		if err != nil {
+			return fmt.Errorf("file does not exist")
+		}
+		if info.IsDir() {
+			return fmt.Errorf("is a directory, not a file")
+		}
+		return nil
+	})
+}
+
+// FlagDirExists requires the value of a flag to be the path of an existing
+// directory. For flags with the BashCompSubdirsInDir annotation, relative
+// paths are resolved against the directory of the annotation, like the
+// completion does.
+func FlagDirExists(f *pflag.Flag) error {
+	var base string
+	if dirs := f.Annotations[BashCompSubdirsInDir]; len(dirs) == 1 {
+		base = dirs[0]
+	}
+	return eachFlagValue(f, func(value string) error {
+		path := value
+		if len(base) > 0 && !filepath.IsAbs(path) {
+			path = filepath.Join(base, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil || !info.IsDir() {
+			return fmt.Errorf("directory does not exist")
+		}
+		return nil
+	})
+}
+
+// FlagFileExtension requires the value of a flag to have one of the filename
+// extensions of its BashCompFilenameExt annotation, set with MarkFlagFilename.
+// Flags without extensions accept any value.
+func FlagFileExtension(f *pflag.Flag) error {
+	exts := f.Annotations[BashCompFilenameExt]
+	if len(exts) == 0 {
+		return nil
+	}
+	return eachFlagValue(f, func(value string) error {
+		ext := strings.TrimPrefix(filepath.Ext(value), ".")
+		for _, e := range exts {
+			if strings.EqualFold(ext, strings.TrimPrefix(e, ".")) {
+				return nil
+			}
+		}
+		return fmt.Errorf("must have one of the extensions %s", strings.Join(exts, ", "))
+	})
+}
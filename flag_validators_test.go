@@ -0,0 +1,134 @@
+package cobra
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestFlagValidatorsAggregated(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().IntP("port", "p", 80, "")
+	rootCmd.Flags().String("name", "", "")
+	rootCmd.Flags().String("endpoint", "", "")
+	rootCmd.AddFlagValidator("port", FlagInRange(1, 65535))
+	rootCmd.AddFlagValidator("name", FlagMatches("^[a-z]+$"))
+	rootCmd.AddFlagValidator("endpoint", FlagURL)
+
+	_, err := executeCommand(rootCmd, "--port", "70000", "--name", "Bob", "--endpoint", "example.com")
+	expected := `invalid argument "example.com" for "--endpoint" flag: must be a URL with a scheme and a host` + "\n" +
+		`invalid argument "Bob" for "--name" flag: must match ^[a-z]+$` + "\n" +
+		`invalid argument "70000" for "-p, --port" flag: must be between 1 and 65535`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "--port", "8080", "--name", "bob", "--endpoint", "https://example.com")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestFlagValidatorsBeforeHooks(t *testing.T) {
+	var hookRan bool
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().Int("port", 80, "")
+	rootCmd.AddFlagValidator("port", FlagInRange(1, 65535))
+	childCmd := &Command{
+		Use:     "child",
+		PreRunE: func(*Command, []string) error { hookRan = true; return nil },
+		Run:     emptyRun,
+	}
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetFlagErrorFunc(func(c *Command, err error) error {
+		return errors.New("flag error: " + err.Error())
+	})
+
+	_, err := executeCommand(rootCmd, "child", "--port", "0")
+	expected := `flag error: invalid argument "0" for "--port" flag: must be between 1 and 65535`
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+	if hookRan {
+		t.Error("Expected the hooks not to run")
+	}
+}
+
+func TestFlagValidatorsOfLocalFlags(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().Int("port", 80, "")
+	rootCmd.AddFlagValidator("port", FlagInRange(1, 100))
+	childCmd := &Command{Use: "child", Run: emptyRun}
+	childCmd.Flags().Int("port", 8080, "")
+	rootCmd.AddCommand(childCmd)
+
+	if _, err := executeCommand(rootCmd, "child", "--port", "9000"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestFlagValidatorsSkipUnchangedFlags(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().String("config", "missing.yaml", "")
+	rootCmd.AddFlagValidator("config", FlagFileExists)
+
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestFlagFileValidators(t *testing.T) {
+	tmpdir, err := ioutil.TempDir("", "cobra-flag-validators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+	file := filepath.Join(tmpdir, "config.json")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(tmpdir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("file", "", "")
+	fs.String("dir", "", "")
+	fs.StringSlice("inputs", nil, "")
+	MarkFlagFilename(fs, "file", "yaml", "json")
+	fs.SetAnnotation("dir", BashCompSubdirsInDir, []string{tmpdir})
+
+	tests := []struct {
+		flag      string
+		value     string
+		validator FlagValidator
+		expected  string
+	}{
+		{"file", file, FlagFileExists, ""},
+		{"file", file + ".missing", FlagFileExists, "file does not exist"},
+		{"file", tmpdir, FlagFileExists, "is a directory, not a file"},
+		{"file", file, FlagFileExtension, ""},
+		{"file", "config.txt", FlagFileExtension, "must have one of the extensions yaml, json"},
+		{"dir", "sub", FlagDirExists, ""},
+		{"dir", "other", FlagDirExists, "directory does not exist"},
+		{"inputs", file + "," + tmpdir, FlagFileExists, `"` + tmpdir + `" is a directory, not a file`},
+	}
+	for _, tc := range tests {
+		f := fs.Lookup(tc.flag)
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			sv.Replace(nil)
+		}
+		f.Value.Set(tc.value)
+		err := tc.validator(f)
+		if tc.expected == "" && err != nil {
+			t.Errorf("%s=%s: unexpected error: %v", tc.flag, tc.value, err)
+		} else if tc.expected != "" && (err == nil || err.Error() != tc.expected) {
+			t.Errorf("%s=%s: expected error %q, got %v", tc.flag, tc.value, tc.expected, err)
+		}
+	}
+}
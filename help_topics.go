@@ -0,0 +1,212 @@
+package cobra
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	flag "github.com/spf13/pflag"
+)
+
+// AddHelpTopic adds an additional help topic named name to c, with the given
+// short description and Markdown content. The topic is shown by
+// "help <name>" and listed by "help topics".
+func (c *Command) AddHelpTopic(name, short, content string) *Command {
+	topic := &Command{
+		Use:   name,
+		Short: short,
+		Long:  strings.TrimSpace(content),
+	}
+	c.AddCommand(topic)
+	return topic
+}
+
+// AddHelpTopicsFS adds an additional help topic to c for every Markdown file
+// in dir of fsys, such as an http.Dir or a file system embedded in the
+// program. A file named "config.md" becomes the topic "config", whose short
+// description is the first heading or, without one, the first line of the
+// file.
+func (c *Command) AddHelpTopicsFS(fsys http.FileSystem, dir string) error {
+	d, err := fsys.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	infos, err := d.Readdir(-1)
+	if err != nil {
+		return err
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+
+	for _, info := range infos {
+		if info.IsDir() || path.Ext(info.Name()) != ".md" {
+			continue
+		}
+		f, err := fsys.Open(path.Join(dir, info.Name()))
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadAll(f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(info.Name(), ".md")
+		c.AddHelpTopic(name, markdownTitle(string(content)), string(content))
+	}
+	return nil
+}
+
+// markdownTitle returns the text of the first heading of content or, if it
+// has none, its first non-empty line.
+func markdownTitle(content string) string {
+	first := ""
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") {
+			return strings.TrimSpace(strings.TrimLeft(line, "#"))
+		}
+		if len(first) == 0 {
+			first = line
+		}
+	}
+	return first
+}
+
+// HelpTopics returns the additional help topic commands of c and its
+// descendants, sorted by command path.
+func (c *Command) HelpTopics() []*Command {
+	var topics []*Command
+	var visit func(*Command)
+	visit = func(cmd *Command) {
+		for _, sub := range cmd.Commands() {
+			if sub.IsAdditionalHelpTopicCommand() {
+				topics = append(topics, sub)
+			}
+			visit(sub)
+		}
+	}
+	visit(c)
+	sort.Slice(topics, func(i, j int) bool { return topics[i].CommandPath() < topics[j].CommandPath() })
+	return topics
+}
+
+// HelpMatch is a command or help topic found by SearchHelp.
+type HelpMatch struct {
+	Command *Command
+	// Score ranks the match; higher is better.
+	Score int
+}
+
+// Weights of the places a search term can be found in.
+const (
+	helpSearchNameWeight  = 10
+	helpSearchShortWeight = 5
+	helpSearchFlagWeight  = 3
+	helpSearchLongWeight  = 1
+)
+
+// SearchHelp searches the names, short and long descriptions and flag usages
+// of c, its available descendants and help topics for all the words of term,
+// ignoring case. The matches are ranked by score, then by command path.
+func (c *Command) SearchHelp(term string) []HelpMatch {
+	words := strings.Fields(strings.ToLower(term))
+	if len(words) == 0 {
+		return nil
+	}
+
+	var matches []HelpMatch
+	var visit func(*Command)
+	visit = func(cmd *Command) {
+		if score := helpSearchScore(cmd, words); score > 0 {
+			matches = append(matches, HelpMatch{Command: cmd, Score: score})
+		}
+		for _, sub := range cmd.Commands() {
+			if sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+				visit(sub)
+			}
+		}
+	}
+	visit(c)
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Command.CommandPath() < matches[j].Command.CommandPath()
+	})
+	return matches
+}
+
+// helpSearchScore returns the score of cmd for words, or 0 if one of the
+// words can't be found in its help.
+func helpSearchScore(cmd *Command, words []string) int {
+	name := strings.ToLower(cmd.Name())
+	short := strings.ToLower(cmd.Short)
+	long := strings.ToLower(cmd.Long)
+	var flags []string
+	cmd.LocalFlags().VisitAll(func(f *flag.Flag) {
+		if !f.Hidden {
+			flags = append(flags, strings.ToLower(f.Name+" "+f.Usage))
+		}
+	})
+
+	score := 0
+	for _, word := range words {
+		wordScore := 0
+		if strings.Contains(name, word) {
+			wordScore += helpSearchNameWeight
+		}
+		wordScore += helpSearchShortWeight * strings.Count(short, word)
+		wordScore += helpSearchLongWeight * strings.Count(long, word)
+		for _, f := range flags {
+			wordScore += helpSearchFlagWeight * strings.Count(f, word)
+		}
+		if wordScore == 0 {
+			return 0
+		}
+		score += wordScore
+	}
+	return score
+}
+
+// printHelpTopics prints the help topics of the root command of c.
+func printHelpTopics(c *Command) {
+	out := c.OutOrStdout()
+	topics := c.Root().HelpTopics()
+	if len(topics) == 0 {
+		fmt.Fprintln(out, "No help topics.")
+		return
+	}
+	fmt.Fprintln(out, "Help topics:")
+	for _, topic := range topics {
+		fmt.Fprintf(out, "  %s %s\n", rpad(topic.CommandPath(), topic.CommandPathPadding()), topic.Short)
+	}
+}
+
+// printHelpSearch prints the results of searching the help of the root
+// command of c for term.
+func printHelpSearch(c *Command, term string) {
+	out := c.OutOrStdout()
+	matches := c.Root().SearchHelp(term)
+	if len(matches) == 0 {
+		fmt.Fprintf(out, "No help found for %q.\n", term)
+		return
+	}
+
+	padding := 0
+	for _, m := range matches {
+		if l := len(m.Command.CommandPath()); l > padding {
+			padding = l
+		}
+	}
+	fmt.Fprintf(out, "Help matching %q:\n", term)
+	for _, m := range matches {
+		fmt.Fprintf(out, "  %s %s\n", rpad(m.Command.CommandPath(), padding), m.Command.Short)
+	}
+}
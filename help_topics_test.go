@@ -0,0 +1,145 @@
+package cobra
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHelpTopics(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "deploy", Run: emptyRun})
+	rootCmd.AddHelpTopic("environment", "Environment variables", "# Environment variables\n\nSet REGION to choose where to deploy by default.\n")
+
+	output, stderr, _, err := executeCommandSplit(rootCmd, "help", "topics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Help topics:\n  root environment Environment variables\n"; output != expected {
+		t.Errorf("Expected %q on stdout, got %q", expected, output)
+	}
+	if stderr != "" {
+		t.Errorf("Unexpected stderr: %q", stderr)
+	}
+
+	output, err = executeCommand(rootCmd, "help", "environment")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "Set REGION to choose where to deploy by default.")
+	checkStringOmits(t, output, "Usage:")
+}
+
+func TestAddHelpTopicsFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cobra-help-topics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"config.md":  "Intro\n\n# Configuration\n\nThe configuration file.\n",
+		"notes.md":   "\nRelease notes\n\nNothing yet.\n",
+		"ignore.txt": "not a topic",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	if err := rootCmd.AddHelpTopicsFS(http.Dir(dir), "/"); err != nil {
+		t.Fatal(err)
+	}
+
+	topics := rootCmd.HelpTopics()
+	if len(topics) != 2 {
+		t.Fatalf("Expected 2 topics, got %d", len(topics))
+	}
+	if topics[0].Name() != "config" || topics[0].Short != "Configuration" {
+		t.Errorf("Unexpected topic %q: %q", topics[0].Name(), topics[0].Short)
+	}
+	if topics[1].Name() != "notes" || topics[1].Short != "Release notes" {
+		t.Errorf("Unexpected topic %q: %q", topics[1].Name(), topics[1].Short)
+	}
+
+	if err := rootCmd.AddHelpTopicsFS(http.Dir(dir), "/missing"); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestSearchHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	deployCmd := &Command{Use: "deploy", Short: "Deploy the application", Long: "Deploy builds and uploads the application.", Run: emptyRun}
+	deployCmd.Flags().String("region", "", "region to deploy the build to")
+	buildCmd := &Command{Use: "build", Short: "Build the application", Run: emptyRun}
+	rootCmd.AddCommand(deployCmd, buildCmd)
+	rootCmd.AddHelpTopic("environment", "Environment variables", "Set REGION to choose where to deploy by default.")
+
+	var paths []string
+	for _, m := range rootCmd.SearchHelp("Deploy") {
+		paths = append(paths, m.Command.CommandPath())
+	}
+	// The name outranks the flag usage, which outranks the topic content.
+	expected := []string{"root deploy", "root environment"}
+	if len(paths) != len(expected) || paths[0] != expected[0] || paths[1] != expected[1] {
+		t.Errorf("Expected matches %v, got %v", expected, paths)
+	}
+
+	if matches := rootCmd.SearchHelp("build region"); len(matches) != 1 || matches[0].Command.Name() != "deploy" {
+		t.Errorf("Expected only deploy to match all words, got %v", matches)
+	}
+	if matches := rootCmd.SearchHelp("  "); len(matches) != 0 {
+		t.Errorf("Expected no matches for an empty term, got %v", matches)
+	}
+}
+
+func TestHelpSearchFlag(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(
+		&Command{Use: "deploy", Short: "Deploy the application", Long: "Deploy builds and uploads the application.", Run: emptyRun},
+		&Command{Use: "build", Short: "Build the application", Run: emptyRun},
+	)
+	rootCmd.AddHelpTopic("environment", "Environment variables", "Set REGION to choose the region.")
+
+	output, stderr, _, err := executeCommandSplit(rootCmd, "help", "--search", "application")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `Help matching "application":
+  root deploy Deploy the application
+  root build  Build the application
+`
+	if output != expected {
+		t.Errorf("Expected %q on stdout, got %q", expected, output)
+	}
+	if stderr != "" {
+		t.Errorf("Unexpected stderr: %q", stderr)
+	}
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "help", "--search", "kubernetes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "No help found for \"kubernetes\".\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestHelpCommandWithoutHelpTopics(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "deploy", Run: emptyRun})
+
+	output, err := executeCommand(rootCmd, "help", "help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "Simply type root help [path to command] for full details.\n")
+	checkStringContains(t, output, "root help [command] [flags]")
+	checkStringOmits(t, output, "topics")
+	checkStringOmits(t, output, "--search")
+}
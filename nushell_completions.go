@@ -0,0 +1,218 @@
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// GenNushellCompletionFile generates a nushell completion file.
+func (c *Command) GenNushellCompletionFile(filename string) error {
+	outFile, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer outFile.Close()
+
+	return c.GenNushellCompletion(outFile)
+}
+
+// GenNushellCompletion generates a nushell completion file and writes it to
+// the passed writer. The file declares an extern for each available command
+// of the tree of c and each of its aliases, with typed flags and the Short
+// of the command and usage of the flags as descriptions. The values of enum
+// flags and ValidArgs are completed by custom completion commands.
+func (c *Command) GenNushellCompletion(w io.Writer) error {
+	return c.genNushellCompletion(w, true)
+}
+
+// genNushellCompletion writes the completion file of GenNushellCompletion to
+// w, leaving the descriptions out unless includeDesc is true.
+func (c *Command) genNushellCompletion(w io.Writer, includeDesc bool) error {
+	g := &nushellGenerator{completers: make(map[string][]string), includeDesc: includeDesc}
+	g.writeExterns(c)
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "# nushell completion for %s\n", c.Name())
+	fmt.Fprintf(buf, "# Load it with \"use %s.nu *\" in your config.nu.\n", c.Name())
+	names := make([]string, 0, len(g.completers))
+	for name := range g.completers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(buf, "\ndef %s [] {\n  [\n", nushellQuote(name))
+		for _, item := range g.completers[name] {
+			fmt.Fprintf(buf, "    %s\n", item)
+		}
+		buf.WriteString("  ]\n}\n")
+	}
+	g.externs.WriteTo(buf)
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// nushellGenerator collects the externs and completion commands of a
+// nushell completion file.
+type nushellGenerator struct {
+	externs bytes.Buffer
+	// completers maps the name of each completion command to the items of
+	// the list it returns.
+	completers map[string][]string
+	// includeDesc adds the descriptions of commands, flags and arguments.
+	includeDesc bool
+}
+
+func (g *nushellGenerator) writeExterns(cmd *Command) {
+	names := []string{cmd.CommandPath()}
+	if cmd.HasParent() {
+		for _, alias := range cmd.Aliases {
+			if !cmd.IsDeprecatedAlias(alias) {
+				names = append(names, cmd.Parent().CommandPath()+" "+alias)
+			}
+		}
+	}
+	params := g.params(cmd)
+	for _, name := range names {
+		g.externs.WriteString("\n")
+		if len(cmd.Short) > 0 && g.includeDesc {
+			fmt.Fprintf(&g.externs, "# %s\n", nushellComment(cmd.Short))
+		}
+		fmt.Fprintf(&g.externs, "export extern %s [\n", nushellQuote(name))
+		for _, param := range params {
+			fmt.Fprintf(&g.externs, "  %s\n", param)
+		}
+		g.externs.WriteString("]\n")
+	}
+
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() || sub == cmd.helpCommand {
+			continue
+		}
+		g.writeExterns(sub)
+	}
+}
+
+// params returns the parameters of the extern of cmd: its flags, local
+// ones first, and its positional arguments.
+func (g *nushellGenerator) params(cmd *Command) []string {
+	var flags []string
+	visit := func(f *pflag.Flag) {
+		if f.Hidden || len(f.Deprecated) > 0 || !cmd.FlagAllowed(f) {
+			return
+		}
+		flags = append(flags, g.flagParam(cmd, f))
+	}
+	cmd.NonInheritedFlags().VisitAll(visit)
+	cmd.InheritedFlags().VisitAll(visit)
+	params := alignNushellComments(flags)
+
+	if cmd.HasAvailableSubCommands() && len(cmd.ValidArgs) == 0 {
+		// Nushell finds the externs of the subcommands by their names.
+		return params
+	}
+	args := "...args: string"
+	var items []string
+	for _, arg := range cmd.ValidArgs {
+		value := validArgValue(arg)
+		if cmd.IsDeprecatedValidArg(value) {
+			continue
+		}
+		item := fmt.Sprintf("{ value: %s }", nushellQuote(value))
+		if desc := validArgDescription(arg); len(desc) > 0 && g.includeDesc {
+			item = fmt.Sprintf("{ value: %s, description: %s }", nushellQuote(value), nushellQuote(desc))
+		}
+		items = append(items, item)
+	}
+	if len(items) > 0 {
+		completer := "nu-complete " + cmd.CommandPath()
+		g.completers[completer] = items
+		args += "@" + nushellQuote(completer)
+	}
+	return append(params, args)
+}
+
+// flagParam returns the parameter of the flag f of cmd, with its usage as a
+// comment.
+func (g *nushellGenerator) flagParam(cmd *Command, f *pflag.Flag) string {
+	param := "--" + f.Name
+	if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+		param += "(-" + f.Shorthand + ")"
+	}
+	if len(f.NoOptDefVal) == 0 {
+		param += ": " + nushellFlagType(f)
+		if values := flagEnumValues(f); len(values) > 0 {
+			owner := cmd.persistentFlagOwner(f)
+			if owner == nil {
+				owner = cmd
+			}
+			completer := "nu-complete " + owner.CommandPath() + " " + f.Name
+			items := make([]string, len(values))
+			for i, v := range values {
+				items[i] = nushellQuote(v)
+			}
+			g.completers[completer] = items
+			param += "@" + nushellQuote(completer)
+		}
+	}
+	if usage := nushellComment(f.Usage); len(usage) > 0 && g.includeDesc {
+		param += "\t# " + usage
+	}
+	return param
+}
+
+// nushellFlagType returns the nushell type of the value of f.
+func nushellFlagType(f *pflag.Flag) string {
+	if _, ok := f.Annotations[BashCompFilenameExt]; ok {
+		return "path"
+	}
+	if _, ok := f.Annotations[BashCompSubdirsInDir]; ok {
+		return "directory"
+	}
+	switch f.Value.Type() {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return "int"
+	case "float32", "float64":
+		return "float"
+	case "bool":
+		// Boolean flags with a value, like --flag=false.
+		return "bool"
+	default:
+		// Go durations, slices, IPs and the like are passed on as strings.
+		return "string"
+	}
+}
+
+// alignNushellComments aligns the comments of params, which follow a tab.
+func alignNushellComments(params []string) []string {
+	width := 0
+	for _, param := range params {
+		if i := strings.Index(param, "\t"); i > width {
+			width = i
+		}
+	}
+	aligned := make([]string, len(params))
+	for i, param := range params {
+		if j := strings.Index(param, "\t"); j >= 0 {
+			param = fmt.Sprintf("%-*s  %s", width, param[:j], param[j+1:])
+		}
+		aligned[i] = param
+	}
+	return aligned
+}
+
+// nushellComment returns the first line of s, for a comment.
+func nushellComment(s string) string {
+	return strings.TrimSpace(strings.SplitN(s, "\n", 2)[0])
+}
+
+// nushellQuote quotes s with double quotes.
+func nushellQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
@@ -0,0 +1,109 @@
+package cobra
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNushellCompletion(t *testing.T) {
+	tcs := []struct {
+		name                string
+		root                *Command
+		expectedExpressions []string
+	}{
+		{
+			name: "trivial",
+			root: &Command{Use: "trivial"},
+			expectedExpressions: []string{
+				"# nushell completion for trivial\n# Load it with \"use trivial.nu *\" in your config.nu.\n",
+				"export extern \"trivial\" [\n  ...args: string\n]\n",
+			},
+		},
+		{
+			name: "tree",
+			root: func() *Command {
+				r := &Command{Use: "app", Short: "Manage \"resources\"", Run: emptyRun}
+				r.PersistentFlags().StringP("config", "c", "", "config file")
+				r.MarkPersistentFlagFilename("config", "yaml")
+				r.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+
+				configCmd := &Command{Use: "config", Short: "Manage the configuration"}
+				configCmd.AddCommand(&Command{Use: "set", Short: "Set a configuration value", Run: emptyRun})
+				r.AddCommand(configCmd, &Command{Use: "hidden", Hidden: true, Run: emptyRun})
+				return r
+			}(),
+			expectedExpressions: []string{
+				"# Manage \"resources\"\nexport extern \"app\" [\n" +
+					"  --config(-c): path  # config file\n" +
+					"  --verbose(-v)       # verbose output\n" +
+					"]\n",
+				// Commands with subcommands take no arguments, so nushell
+				// completes the subcommands.
+				"export extern \"app config\" [\n  --config(-c): path  # config file\n  --verbose(-v)       # verbose output\n]\n",
+				"# Set a configuration value\nexport extern \"app config set\" [\n",
+			},
+		},
+		{
+			name: "flags and valid args",
+			root: func() *Command {
+				r := &Command{Use: "app", Run: emptyRun}
+				r.PersistentFlags().String("color", "auto", "when to color the output")
+				r.MarkPersistentFlagEnum("color", "auto", "always", "never")
+				getCmd := &Command{
+					Use:       "get",
+					Aliases:   []string{"g"},
+					Short:     "Get resources",
+					ValidArgs: []string{"pod\tA group of containers", "node"},
+					Run:       emptyRun,
+				}
+				getCmd.Flags().StringP("output", "o", "table", "output format\nwith more details")
+				getCmd.MarkFlagEnum("output", "json", "table")
+				getCmd.Flags().Int("limit", 0, "maximum number of resources")
+				getCmd.Flags().Float64("ratio", 0, "")
+				getCmd.Flags().StringSlice("tags", nil, "tags to filter by")
+				getCmd.Flags().String("dir", "", "output directory")
+				getCmd.Flags().SetAnnotation("dir", BashCompSubdirsInDir, []string{})
+				getCmd.Flags().String("secret", "", "")
+				getCmd.Flags().MarkHidden("secret")
+				r.AddCommand(getCmd)
+				return r
+			}(),
+			expectedExpressions: []string{
+				"def \"nu-complete app color\" [] {\n  [\n    \"auto\"\n    \"always\"\n    \"never\"\n  ]\n}\n",
+				"def \"nu-complete app get\" [] {\n  [\n" +
+					"    { value: \"pod\", description: \"A group of containers\" }\n" +
+					"    { value: \"node\" }\n" +
+					"  ]\n}\n",
+				"# Get resources\nexport extern \"app get\" [\n" +
+					"  --dir: directory                                   # output directory\n" +
+					"  --limit: int                                       # maximum number of resources\n" +
+					"  --output(-o): string@\"nu-complete app get output\"  # output format\n" +
+					"  --ratio: float\n" +
+					"  --tags: string                                     # tags to filter by\n" +
+					"  --color: string@\"nu-complete app color\"            # when to color the output (one of auto, always, never)\n" +
+					"  ...args: string@\"nu-complete app get\"\n" +
+					"]\n",
+				"# Get resources\nexport extern \"app g\" [\n",
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := tc.root.GenNushellCompletion(buf); err != nil {
+				t.Fatal(err)
+			}
+			output := buf.String()
+
+			for _, expectedExpression := range tc.expectedExpressions {
+				if !strings.Contains(output, expectedExpression) {
+					t.Errorf("Expected completion to contain %q somewhere; got %q", expectedExpression, output)
+				}
+			}
+			checkStringOmits(t, output, "secret")
+			checkStringOmits(t, output, "hidden")
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package cobra
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// DefaultPager is the pager used for help output when neither the PAGER
+// environment variable nor SetHelpPager name one.
+var DefaultPager = "less -FRX"
+
+// NoPagerEnv is the name of the environment variable that, when set to a
+// non-empty value, disables paging of help output.
+var NoPagerEnv = "NO_PAGER"
+
+// isTerminal and terminalHeight can be replaced in tests.
+var (
+	isTerminal     = fileIsTerminal
+	terminalHeight = fileTerminalHeight
+)
+
+// SetHelpPager enables paging of the output of the default help function of
+// c and its subcommands. When the output is a terminal and is taller than its
+// window, it is piped through the command named by the PAGER environment
+// variable or, if it is empty, by pager, or by DefaultPager. Paging is
+// disabled when the output is redirected or the NO_PAGER environment variable
+// is set.
+func (c *Command) SetHelpPager(pager string) {
+	c.helpPagerEnabled = true
+	c.helpPager = pager
+}
+
+// helpPagerCommand returns the pager to use for the help of c, or an empty
+// string if paging is not enabled for c.
+func (c *Command) helpPagerCommand() string {
+	if !c.helpPagerEnabled {
+		if c.HasParent() {
+			return c.Parent().helpPagerCommand()
+		}
+		return ""
+	}
+	if pager := os.Getenv("PAGER"); len(pager) > 0 {
+		return pager
+	}
+	if len(c.helpPager) > 0 {
+		return c.helpPager
+	}
+	return DefaultPager
+}
+
+// writePaged writes text to the output of c, through a pager if paging is
+// enabled and text does not fit in the terminal the output goes to.
+func (c *Command) writePaged(text []byte) error {
+	out := c.OutOrStdout()
+	pager := c.helpPagerCommand()
+	if len(pager) == 0 || len(os.Getenv(NoPagerEnv)) > 0 || !isTerminal(out) {
+		_, err := out.Write(text)
+		return err
+	}
+	if height := terminalHeight(out); height > 0 && bytes.Count(text, []byte("\n")) < height {
+		_, err := out.Write(text)
+		return err
+	}
+
+	words, err := splitShellWords(pager)
+	if err != nil || len(words) == 0 {
+		_, err := out.Write(text)
+		return err
+	}
+	cmd := exec.Command(words[0], words[1:]...)
+	cmd.Stdin = bytes.NewReader(text)
+	cmd.Stdout = out
+	cmd.Stderr = c.ErrOrStderr()
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			// The pager ran, for instance until the user quit it.
+			return nil
+		}
+		// The pager could not be started, so show the text directly.
+		_, err = out.Write(text)
+		return err
+	}
+	return nil
+}
+
+// fileIsTerminal determines if w is a terminal.
+func fileIsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,16 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package cobra
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+// fileTerminalHeight returns the number of rows given by the LINES
+// environment variable, or 0 if it is not set.
+func fileTerminalHeight(w io.Writer) int {
+	lines, _ := strconv.Atoi(os.Getenv("LINES"))
+	return lines
+}
@@ -0,0 +1,136 @@
+package cobra
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// setUpPagerTest makes every writer look like a terminal of the given
+// height and points PAGER at a stub script that marks its output.
+func setUpPagerTest(t *testing.T, height int) (stub string, tearDown func()) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the stub pager is a shell script")
+	}
+
+	dir, err := ioutil.TempDir("", "cobra-pager")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub = filepath.Join(dir, "pager")
+	script := "#!/bin/sh\necho \"paged $*\"\ncat\n"
+	if err := ioutil.WriteFile(stub, []byte(script), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	oldIsTerminal, oldTerminalHeight := isTerminal, terminalHeight
+	isTerminal = func(io.Writer) bool { return true }
+	terminalHeight = func(io.Writer) int { return height }
+	os.Setenv("PAGER", stub)
+
+	return stub, func() {
+		isTerminal, terminalHeight = oldIsTerminal, oldTerminalHeight
+		os.Unsetenv("PAGER")
+		os.RemoveAll(dir)
+	}
+}
+
+func TestHelpPager(t *testing.T) {
+	_, tearDown := setUpPagerTest(t, 5)
+	defer tearDown()
+
+	rootCmd := &Command{Use: "root", Long: strings.Repeat("line\n", 10), Run: emptyRun}
+	rootCmd.SetHelpPager("")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "paged \nline\n") {
+		t.Errorf("Expected output to go through the pager, got %q", output)
+	}
+	checkStringContains(t, output, "Usage:")
+}
+
+func TestHelpPagerFromSetHelpPager(t *testing.T) {
+	stub, tearDown := setUpPagerTest(t, 5)
+	defer tearDown()
+	os.Unsetenv("PAGER")
+
+	rootCmd := &Command{Use: "root", Long: strings.Repeat("line\n", 10), Run: emptyRun}
+	rootCmd.SetHelpPager(stub + " --quit-if-one-screen")
+	subCmd := &Command{Use: "sub", Long: rootCmd.Long, Run: emptyRun}
+	rootCmd.AddCommand(subCmd)
+
+	output, err := executeCommand(rootCmd, "sub", "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "paged --quit-if-one-screen\n") {
+		t.Errorf("Expected output to go through the pager, got %q", output)
+	}
+}
+
+func TestHelpPagerNotUsed(t *testing.T) {
+	_, tearDown := setUpPagerTest(t, 5)
+	defer tearDown()
+
+	tests := []struct {
+		name  string
+		setUp func(*Command) func()
+	}{
+		{"short output", func(*Command) func() {
+			terminalHeight = func(io.Writer) int { return 50 }
+			return func() { terminalHeight = func(io.Writer) int { return 5 } }
+		}},
+		{"NO_PAGER", func(*Command) func() {
+			os.Setenv(NoPagerEnv, "1")
+			return func() { os.Unsetenv(NoPagerEnv) }
+		}},
+		{"redirected output", func(*Command) func() {
+			isTerminal = fileIsTerminal
+			return func() { isTerminal = func(io.Writer) bool { return true } }
+		}},
+		{"not enabled", func(c *Command) func() {
+			c.helpPagerEnabled = false
+			return func() {}
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rootCmd := &Command{Use: "root", Long: strings.Repeat("line\n", 10), Run: emptyRun}
+			rootCmd.SetHelpPager("")
+			defer tc.setUp(rootCmd)()
+
+			output, err := executeCommand(rootCmd, "--help")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if strings.HasPrefix(output, "paged") {
+				t.Errorf("Expected output not to be paged, got %q", output)
+			}
+		})
+	}
+}
+
+func TestHelpPagerNotFound(t *testing.T) {
+	_, tearDown := setUpPagerTest(t, 5)
+	defer tearDown()
+	os.Setenv("PAGER", "cobra-no-such-pager")
+
+	rootCmd := &Command{Use: "root", Long: strings.Repeat("line\n", 10), Run: emptyRun}
+	rootCmd.SetHelpPager("")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "line\n") {
+		t.Errorf("Expected the help to be printed directly, got %q", output)
+	}
+}
@@ -0,0 +1,25 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package cobra
+
+import (
+	"io"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// fileTerminalHeight returns the number of rows of the terminal w writes
+// to, or 0 if it can't be determined.
+func fileTerminalHeight(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	var ws struct{ Row, Col, Xpixel, Ypixel uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Row)
+}
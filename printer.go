@@ -0,0 +1,269 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// The output formats of a Printer.
+const (
+	OutputJSON     = "json"
+	OutputYAML     = "yaml"
+	OutputTable    = "table"
+	OutputTemplate = "template"
+)
+
+// OutputFlagName is the name of the flag added by AddOutputFlag.
+var OutputFlagName = "output"
+
+// Printer writes values in one of the output formats.
+type Printer struct {
+	// Format is one of OutputJSON, OutputYAML, OutputTable or OutputTemplate.
+	// It defaults to OutputTable.
+	Format string
+	// Template is the Go template used by OutputTemplate. It can use the same
+	// functions as the help and usage templates.
+	Template string
+	// Columns selects the columns, and their order, of OutputTable. Columns
+	// are matched case-insensitively against the column names. All columns
+	// are printed if it is empty.
+	Columns []string
+}
+
+// AddOutputFlag adds a --output (-o) flag to c, which selects the format
+// of PrintOutput from json, yaml, table and template, with table as the
+// default. It also adds a --template flag, for the Go template of the
+// template format, and a --columns flag, which selects the columns of the
+// table format.
+func (c *Command) AddOutputFlag() {
+	c.Flags().StringP(OutputFlagName, "o", OutputTable, "output format")
+	c.MarkFlagEnum(OutputFlagName, OutputJSON, OutputYAML, OutputTable, OutputTemplate)
+	c.Flags().String("template", "", "Go template for the template output format")
+	c.Flags().StringSlice("columns", nil, "columns of the table output format")
+}
+
+// OutputPrinter returns the printer configured by the flags added with
+// AddOutputFlag, or a table printer if c has no such flags.
+func (c *Command) OutputPrinter() *Printer {
+	p := &Printer{Format: OutputTable}
+	if f := c.Flags().Lookup(OutputFlagName); f != nil {
+		p.Format = f.Value.String()
+	}
+	if f := c.Flags().Lookup("template"); f != nil {
+		p.Template = f.Value.String()
+	}
+	if columns, err := c.Flags().GetStringSlice("columns"); err == nil {
+		p.Columns = columns
+	}
+	return p
+}
+
+// PrintOutput writes v to the output of c in the format selected with the
+// flags added by AddOutputFlag.
+func (c *Command) PrintOutput(v interface{}) error {
+	return c.OutputPrinter().Print(c.OutOrStdout(), v)
+}
+
+// Print writes v to w in the format of p.
+//
+// The table format prints a row for each element of v if it is a slice or
+// an array, and a single row otherwise. The columns of a row are the
+// exported fields of structs, named by their json tag if they have one, the
+// keys of maps, or a single VALUE column for other values.
+func (p *Printer) Print(w io.Writer, v interface{}) error {
+	switch p.Format {
+	case OutputJSON:
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+		return err
+	case OutputYAML:
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case OutputTemplate:
+		if len(p.Template) == 0 {
+			return fmt.Errorf("the template output format requires a template")
+		}
+		t, err := template.New("output").Funcs(templateFuncs).Parse(p.Template)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, v)
+	case OutputTable, "":
+		return p.printTable(w, v)
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of %s, %s, %s or %s",
+			p.Format, OutputJSON, OutputYAML, OutputTable, OutputTemplate)
+	}
+}
+
+// tableColumn is a column of a table and how to get its cell from a row.
+type tableColumn struct {
+	name string
+	cell func(row reflect.Value) string
+}
+
+func (p *Printer) printTable(w io.Writer, v interface{}) error {
+	rows, elemType := tableRows(v)
+	columns, err := selectColumns(tableColumns(rows, elemType), p.Columns)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	cells := make([]string, len(columns))
+	for i, col := range columns {
+		cells[i] = strings.ToUpper(col.name)
+	}
+	fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	for _, row := range rows {
+		for i, col := range columns {
+			cells[i] = col.cell(row)
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+// tableRows returns the rows for v and their type, with pointers and
+// interfaces resolved.
+func tableRows(v interface{}) (rows []reflect.Value, elemType reflect.Type) {
+	rv := indirect(reflect.ValueOf(v))
+	if !rv.IsValid() {
+		return nil, nil
+	}
+	if (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) || rv.Type().Elem().Kind() == reflect.Uint8 {
+		return []reflect.Value{rv}, rv.Type()
+	}
+	elemType = rv.Type().Elem()
+	for elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	for i := 0; i < rv.Len(); i++ {
+		rows = append(rows, indirect(rv.Index(i)))
+	}
+	return rows, elemType
+}
+
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// tableColumns returns the columns of rows of type elemType.
+func tableColumns(rows []reflect.Value, elemType reflect.Type) []tableColumn {
+	if elemType == nil {
+		return nil
+	}
+	switch elemType.Kind() {
+	case reflect.Struct:
+		var columns []tableColumn
+		for i := 0; i < elemType.NumField(); i++ {
+			field := elemType.Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			name := field.Name
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if len(tag) > 0 {
+				name = tag
+			}
+			index := i
+			columns = append(columns, tableColumn{name, func(row reflect.Value) string {
+				if !row.IsValid() {
+					return ""
+				}
+				return formatCell(row.Field(index))
+			}})
+		}
+		return columns
+	case reflect.Map:
+		keys := make(map[string]reflect.Value)
+		var names []string
+		for _, row := range rows {
+			if !row.IsValid() {
+				continue
+			}
+			for _, key := range row.MapKeys() {
+				name := fmt.Sprint(key.Interface())
+				if _, ok := keys[name]; !ok {
+					keys[name] = key
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		columns := make([]tableColumn, len(names))
+		for i, name := range names {
+			key := keys[name]
+			columns[i] = tableColumn{name, func(row reflect.Value) string {
+				if !row.IsValid() {
+					return ""
+				}
+				return formatCell(row.MapIndex(key))
+			}}
+		}
+		return columns
+	default:
+		return []tableColumn{{"value", formatCell}}
+	}
+}
+
+// selectColumns returns the columns named by names, in their order, or all
+// columns if names is empty.
+func selectColumns(columns []tableColumn, names []string) ([]tableColumn, error) {
+	if len(names) == 0 {
+		return columns, nil
+	}
+	var selected []tableColumn
+	for _, name := range names {
+		found := false
+		for _, col := range columns {
+			if strings.EqualFold(col.name, name) {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			available := make([]string, len(columns))
+			for i, col := range columns {
+				available[i] = col.name
+			}
+			return nil, fmt.Errorf("unknown column %q, must be one of %s", name, strings.Join(available, ", "))
+		}
+	}
+	return selected, nil
+}
+
+// formatCell formats v as the cell of a table.
+func formatCell(v reflect.Value) string {
+	v = indirect(v)
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,143 @@
+package cobra
+
+import (
+	"bytes"
+	"testing"
+)
+
+type printerTestItem struct {
+	Name    string `json:"name" yaml:"name"`
+	Size    int    `json:"size" yaml:"size"`
+	Secret  string `json:"-" yaml:"-"`
+	Comment string
+	hidden  string
+}
+
+var printerTestItems = []*printerTestItem{
+	{Name: "alpha", Size: 10, Comment: "first"},
+	{Name: "beta-long-name", Size: 2000},
+}
+
+func TestPrintOutputTable(t *testing.T) {
+	rootCmd := &Command{Use: "root", RunE: func(cmd *Command, args []string) error {
+		return cmd.PrintOutput(printerTestItems)
+	}}
+	rootCmd.AddOutputFlag()
+
+	output, err := executeCommand(rootCmd)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := "NAME             SIZE   COMMENT\n" +
+		"alpha            10     first\n" +
+		"beta-long-name   2000   \n"
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "--columns", "size,NAME")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected = "SIZE   NAME\n" +
+		"10     alpha\n" +
+		"2000   beta-long-name\n"
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "--columns", "colour")
+	expected = "unknown column \"colour\", must be one of name, size, Comment"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestPrintOutputFormats(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-o", "json"}, `[
+  {
+    "name": "alpha",
+    "size": 10,
+    "Comment": "first"
+  },
+  {
+    "name": "beta-long-name",
+    "size": 2000,
+    "Comment": ""
+  }
+]
+`},
+		{[]string{"-o", "yaml"}, `- name: alpha
+  size: 10
+  comment: first
+- name: beta-long-name
+  size: 2000
+  comment: ""
+`},
+		{[]string{"-o", "template", "--template", `{{range .}}{{rpad .Name 16}}{{.Size}}{{"\n"}}{{end}}`},
+			"alpha           10\nbeta-long-name  2000\n"},
+	}
+	for _, tc := range tests {
+		rootCmd := &Command{Use: "root", RunE: func(cmd *Command, args []string) error {
+			return cmd.PrintOutput(printerTestItems)
+		}}
+		rootCmd.AddOutputFlag()
+
+		output, err := executeCommand(rootCmd, tc.args...)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.args, err)
+			continue
+		}
+		if output != tc.expected {
+			t.Errorf("%q: expected:\n%s\ngot:\n%s", tc.args, tc.expected, output)
+		}
+	}
+}
+
+func TestPrintOutputInvalidFormat(t *testing.T) {
+	rootCmd := &Command{Use: "root", RunE: func(cmd *Command, args []string) error {
+		return cmd.PrintOutput(printerTestItems)
+	}}
+	rootCmd.AddOutputFlag()
+
+	_, err := executeCommand(rootCmd, "-o", "csv")
+	expected := "invalid argument \"csv\" for \"-o, --output\" flag: must be one of json, yaml, table, template"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "-o", "template")
+	if err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
+
+func TestPrinterTableValues(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{printerTestItems[0], "NAME    SIZE   COMMENT\nalpha   10     first\n"},
+		{[]map[string]int{{"b": 2, "a": 1}, {"c": 3}}, "A   B   C\n1   2   \n        3\n"},
+		{[]string{"x", "y"}, "VALUE\nx\ny\n"},
+		{[]printerTestItem{}, "NAME   SIZE   COMMENT\n"},
+		{nil, ""},
+	}
+	for _, tc := range tests {
+		buf := new(bytes.Buffer)
+		if err := (&Printer{}).Print(buf, tc.value); err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.value, err)
+			continue
+		}
+		if buf.String() != tc.expected {
+			t.Errorf("%v: expected:\n%q\ngot:\n%q", tc.value, tc.expected, buf.String())
+		}
+	}
+}
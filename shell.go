@@ -0,0 +1,239 @@
+package cobra
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ShellOptions configures the interactive shell started by RunShell.
+type ShellOptions struct {
+	// Prompt is printed before reading each line. It defaults to the name of
+	// the root command followed by "> ".
+	Prompt string
+
+	// HistoryFile is the file every line that is entered is appended to.
+	// If it is empty, no history is kept.
+	HistoryFile string
+}
+
+// RunShell starts an interactive shell that reads lines from the input of
+// the root command and executes each of them against the command tree, as
+// if it had been given on the command line. The state left behind by each
+// line, such as flag values, is reset before the next one.
+//
+// Lines are split into words like a POSIX shell does, honouring single and
+// double quotes and backslash escapes. Besides the commands of the tree, the
+// shell understands the built-in commands "exit", "help", "history" and
+// "complete", which lists the candidates for completing the last word of the
+// line that follows it, from the same data the completion scripts use.
+// "history" and "complete" give way to subcommands of the root with those
+// names.
+//
+// The shell reads whole lines, so terminals in their usual line mode deliver
+// nothing to it until Enter is pressed: it offers no interactive completion
+// as the tab key does in a shell. For scripted input, a line that ends with
+// a tab character is a completion query like "complete" as well.
+//
+// RunShell returns when the input ends or "exit" is entered. Errors of the
+// executed commands are reported by the commands and don't end the shell.
+func (c *Command) RunShell(opts ShellOptions) error {
+	root := c.Root()
+	root.InitDefaultHelpCmd()
+
+	prompt := opts.Prompt
+	if prompt == "" {
+		prompt = root.Name() + "> "
+	}
+
+	history, err := readShellHistory(opts.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	in := bufio.NewReader(root.InOrStdin())
+	for {
+		fmt.Fprint(root.OutOrStdout(), prompt)
+
+		line, err := in.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(line) == 0 && err == io.EOF {
+			fmt.Fprintln(root.OutOrStdout())
+			return nil
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		if strings.HasSuffix(line, "\t") {
+			root.printShellCompletions(strings.TrimRight(line, "\t"))
+			continue
+		}
+		if rest := strings.TrimLeft(line, " \t"); (rest == "complete" || strings.HasPrefix(rest, "complete ")) && !root.hasSubCommand("complete") {
+			root.printShellCompletions(strings.TrimLeft(strings.TrimPrefix(rest, "complete"), " \t"))
+			continue
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		history = append(history, line)
+		if err := appendShellHistory(opts.HistoryFile, line); err != nil {
+			root.PrintErrln("Error:", err.Error())
+		}
+
+		words, err := splitShellWords(line)
+		if err != nil {
+			root.PrintErrln("Error:", err.Error())
+			continue
+		}
+		switch words[0] {
+		case "exit", "quit":
+			return nil
+		case "history":
+			if root.hasSubCommand("history") {
+				break
+			}
+			for i, entry := range history {
+				fmt.Fprintf(root.OutOrStdout(), "%5d  %s\n", i+1, entry)
+			}
+			continue
+		case "help":
+			if len(words) == 1 {
+				fmt.Fprintln(root.OutOrStdout(), "Shell commands: complete [line], exit, help [command], history")
+				fmt.Fprintln(root.OutOrStdout())
+			}
+			words = append(words[1:], "--help")
+		}
+
+		root.ResetFlagsState()
+		root.SetArgs(words)
+		root.ExecuteC()
+	}
+}
+
+// hasSubCommand reports whether c has a subcommand called name.
+func (c *Command) hasSubCommand(name string) bool {
+	for _, cmd := range c.commands {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// printShellCompletions prints the candidates for completing the last word
+// of line.
+func (c *Command) printShellCompletions(line string) {
+	words, err := splitShellWords(line)
+	if err != nil {
+		return
+	}
+	toComplete := ""
+	if len(words) > 0 && !strings.HasSuffix(line, " ") {
+		toComplete = words[len(words)-1]
+		words = words[:len(words)-1]
+	}
+	candidates := c.completions(words, toComplete)
+	c.ResetFlagsState()
+	if len(candidates) > 0 {
+		fmt.Fprintln(c.OutOrStdout(), strings.Join(candidates, "  "))
+	}
+}
+
+func readShellHistory(filename string) ([]string, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var history []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		history = append(history, scanner.Text())
+	}
+	return history, scanner.Err()
+}
+
+func appendShellHistory(filename, line string) error {
+	if filename == "" {
+		return nil
+	}
+	f, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// splitShellWords splits line into words the way a POSIX shell does,
+// without any expansions. Words are separated by unquoted whitespace.
+// Single quotes preserve everything up to the next single quote, double
+// quotes preserve everything but backslash escapes of \, " and $, and an
+// unquoted backslash preserves the next character.
+func splitShellWords(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' && i+1 < len(runes) && strings.ContainsRune(`\"$`, runes[i+1]) {
+				i++
+				word.WriteRune(runes[i])
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == '\\':
+			if i+1 == len(runes) {
+				return nil, fmt.Errorf("unexpected end of line after \\ in %q", line)
+			}
+			i++
+			word.WriteRune(runes[i])
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in %q", quote, line)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,170 @@
+package cobra
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"", nil},
+		{"  a  b\tc ", []string{"a", "b", "c"}},
+		{`a "b c" 'd e'`, []string{"a", "b c", "d e"}},
+		{`a"b"'c'`, []string{"abc"}},
+		{`"" ''`, []string{"", ""}},
+		{`a\ b \"c\"`, []string{"a b", `"c"`}},
+		{`"a \"b\" \n" 'c \n'`, []string{`a "b" \n`, `c \n`}},
+	}
+	for _, tc := range tests {
+		got, err := splitShellWords(tc.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.line, err)
+		}
+		if !reflect.DeepEqual(got, tc.expected) {
+			t.Errorf("%q: expected %q, got %q", tc.line, tc.expected, got)
+		}
+	}
+
+	for _, line := range []string{`"a`, `'a`, `a\`} {
+		if _, err := splitShellWords(line); err == nil {
+			t.Errorf("%q: expected an error", line)
+		}
+	}
+}
+
+func runShell(rootCmd *Command, opts ShellOptions, input string) string {
+	buf := new(bytes.Buffer)
+	rootCmd.SetOutput(buf)
+	rootCmd.SetIn(strings.NewReader(input))
+	if err := rootCmd.RunShell(opts); err != nil {
+		buf.WriteString("RunShell: " + err.Error())
+	}
+	return buf.String()
+}
+
+func TestRunShell(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	greetCmd := &Command{
+		Use: "greet",
+		Run: func(c *Command, args []string) {
+			name, _ := c.Flags().GetString("name")
+			c.Println("hello", name, strings.Join(args, " "))
+		},
+	}
+	greetCmd.Flags().String("name", "world", "")
+	rootCmd.AddCommand(greetCmd)
+
+	input := "greet --name cobra 'a b'\ngreet\nunknown\n\nexit\ngreet\n"
+	output := runShell(rootCmd, ShellOptions{Prompt: "$ "}, input)
+
+	expected := "$ hello cobra a b\n" +
+		"$ hello world \n" +
+		"$ Error: unknown command \"unknown\" for \"root\"\nRun 'root --help' for usage.\n" +
+		"$ $ "
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+}
+
+func TestRunShellEndOfInput(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "greet", Run: func(c *Command, args []string) { c.Println("hello") }})
+
+	output := runShell(rootCmd, ShellOptions{}, "greet")
+	if output != "root> hello\nroot> \n" {
+		t.Errorf("Unexpected output: %q", output)
+	}
+}
+
+func TestRunShellHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "greet", Run: emptyRun})
+
+	output := runShell(rootCmd, ShellOptions{}, "help\nhelp greet\n")
+
+	checkStringContains(t, output, "Shell commands: complete [line], exit, help [command], history")
+	checkStringContains(t, output, "root [command] --help")
+	checkStringContains(t, output, "root greet [flags]")
+}
+
+func TestRunShellCompletion(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	greetCmd := &Command{Use: "greet", Aliases: []string{"hi"}, Run: emptyRun}
+	greetCmd.Flags().String("name", "", "")
+	greetCmd.Flags().Bool("loud", false, "")
+	rootCmd.AddCommand(greetCmd, &Command{Use: "grow", Run: emptyRun})
+
+	output := runShell(rootCmd, ShellOptions{Prompt: "$ "}, "gr\t\ngreet --\t\ngreet --name \t\n")
+
+	expected := "$ greet  grow\n" +
+		"$ --help  --loud  --name\n" +
+		"$ $ \n"
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+}
+
+func TestRunShellCompleteCommand(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	greetCmd := &Command{Use: "greet", Aliases: []string{"hi"}, Run: emptyRun}
+	greetCmd.Flags().String("name", "", "")
+	greetCmd.Flags().Bool("loud", false, "")
+	rootCmd.AddCommand(greetCmd, &Command{Use: "grow", Run: emptyRun})
+
+	output := runShell(rootCmd, ShellOptions{Prompt: "$ "}, "complete gr\ncomplete greet --\ncomplete\n")
+
+	expected := "$ greet  grow\n" +
+		"$ --help  --loud  --name\n" +
+		"$ greet  grow  help  hi\n" +
+		"$ \n"
+	if output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+}
+
+func TestRunShellHistory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cobra-shell")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	historyFile := filepath.Join(dir, "history")
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "greet", Aliases: []string{"hi"}, Run: emptyRun})
+
+	runShell(rootCmd, ShellOptions{HistoryFile: historyFile}, "greet\n")
+	output := runShell(rootCmd, ShellOptions{HistoryFile: historyFile}, "hi\nhistory\n")
+
+	checkStringContains(t, output, "    1  greet\n    2  hi\n    3  history\n")
+
+	content, err := ioutil.ReadFile(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "greet\nhi\nhistory\n" {
+		t.Errorf("Unexpected history file content: %q", content)
+	}
+}
+
+func TestRunShellSubcommandsOverBuiltins(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	for _, name := range []string{"history", "complete"} {
+		rootCmd.AddCommand(&Command{Use: name, Run: func(c *Command, args []string) {
+			c.Println("ran", c.Name(), strings.Join(args, " "))
+		}})
+	}
+
+	output := runShell(rootCmd, ShellOptions{Prompt: "$ "}, "history\ncomplete a\n")
+	if expected := "$ ran history \n$ ran complete a\n$ \n"; output != expected {
+		t.Errorf("Expected:\n%q\nGot:\n%q", expected, output)
+	}
+}
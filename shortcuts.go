@@ -0,0 +1,118 @@
+package cobra
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Shortcut is a name registered on a command with AddShortcut that runs one
+// of its descendants.
+type Shortcut struct {
+	// Name is the name of the shortcut.
+	Name string
+	// Owner is the command the shortcut is registered on.
+	Owner *Command
+	// Target is the command the shortcut runs.
+	Target *Command
+}
+
+// CommandPath returns the full path of the shortcut, e.g. "tool ls".
+func (s Shortcut) CommandPath() string {
+	return s.Owner.CommandPath() + " " + s.Name
+}
+
+// AddShortcut registers name as a subcommand of c that runs the descendant of
+// c found at path, e.g. "storage objects list", so that "tool ls" behaves
+// exactly like "tool storage objects list". The target keeps its flags and
+// hooks, and its CalledAs returns name. Subcommands of c and their aliases
+// take precedence over shortcuts.
+func (c *Command) AddShortcut(name, path string) {
+	if c.shortcuts == nil {
+		c.shortcuts = make(map[string]string)
+	}
+	c.shortcuts[name] = path
+}
+
+// Shortcuts returns the shortcuts registered on c whose target exists,
+// sorted by name.
+func (c *Command) Shortcuts() []Shortcut {
+	names := make([]string, 0, len(c.shortcuts))
+	for name := range c.shortcuts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var shortcuts []Shortcut
+	for _, name := range names {
+		if target := c.shortcutTarget(name); target != nil {
+			shortcuts = append(shortcuts, Shortcut{Name: name, Owner: c, Target: target})
+		}
+	}
+	return shortcuts
+}
+
+// ShortcutsTo returns the shortcuts registered on the parents of c that run c.
+func (c *Command) ShortcutsTo() []Shortcut {
+	var shortcuts []Shortcut
+	c.VisitParents(func(p *Command) {
+		for _, s := range p.Shortcuts() {
+			if s.Target == c {
+				shortcuts = append(shortcuts, s)
+			}
+		}
+	})
+	return shortcuts
+}
+
+// HasAvailableShortcuts determines if c has shortcuts to available commands.
+func (c *Command) HasAvailableShortcuts() bool {
+	for _, s := range c.Shortcuts() {
+		if s.Target.IsAvailableCommand() {
+			return true
+		}
+	}
+	return false
+}
+
+// ShortcutsUsage returns the shortcuts of c to available commands formatted
+// for the usage message, one per line.
+func (c *Command) ShortcutsUsage() string {
+	buf := new(bytes.Buffer)
+	for _, s := range c.Shortcuts() {
+		if s.Target.IsAvailableCommand() {
+			fmt.Fprintf(buf, "  %s %s\n", rpad(s.Name, c.NamePadding()), s.Target.CommandPath())
+		}
+	}
+	return buf.String()
+}
+
+// shortcutTarget finds the command run by the shortcut name of c, or nil.
+// The path of a shortcut is made of command names and aliases only, so
+// shortcuts can't refer to each other.
+func (c *Command) shortcutTarget(name string) *Command {
+	path, ok := c.shortcuts[name]
+	if !ok {
+		return nil
+	}
+
+	target := c
+	for _, next := range strings.Fields(path) {
+		var found *Command
+		for _, cmd := range target.commands {
+			if cmd.Name() == next || cmd.HasAlias(next) {
+				found = cmd
+				break
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		target = found
+	}
+	if target == c {
+		return nil
+	}
+	return target
+}
@@ -0,0 +1,119 @@
+package cobra
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShortcut(t *testing.T) {
+	var calledAs string
+	var gotArgs []string
+	rootCmd := &Command{Use: "tool", Run: emptyRun}
+	storageCmd := &Command{
+		Use:              "storage",
+		PersistentPreRun: func(*Command, []string) { calledAs += "pre:" },
+	}
+	objectsCmd := &Command{Use: "objects"}
+	listCmd := &Command{
+		Use: "list",
+		Run: func(c *Command, args []string) {
+			calledAs += c.CalledAs()
+			gotArgs = args
+		},
+	}
+	listCmd.Flags().BoolP("long", "l", false, "")
+	objectsCmd.AddCommand(listCmd)
+	storageCmd.AddCommand(objectsCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddShortcut("ls", "storage objects list")
+
+	c, _, err := executeCommandC(rootCmd, "ls", "-l", "bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != listCmd {
+		t.Errorf("Expected to run %q, got %q", listCmd.CommandPath(), c.CommandPath())
+	}
+	if calledAs != "pre:ls" {
+		t.Errorf("Expected the persistent hook to run and CalledAs to be ls, got %q", calledAs)
+	}
+	if !reflect.DeepEqual(gotArgs, []string{"bucket"}) {
+		t.Errorf("Expected args [bucket], got %v", gotArgs)
+	}
+	if long, _ := listCmd.Flags().GetBool("long"); !long {
+		t.Error("Expected -l to be set")
+	}
+}
+
+func TestShortcutWithTraverseChildren(t *testing.T) {
+	rootCmd := &Command{Use: "tool", Run: emptyRun, TraverseChildren: true}
+	storageCmd := &Command{Use: "storage"}
+	listCmd := &Command{Use: "list", Run: emptyRun}
+	storageCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddShortcut("ls", "storage list")
+
+	c, _, err := executeCommandC(rootCmd, "ls", "bucket")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != listCmd {
+		t.Errorf("Expected to run %q, got %q", listCmd.CommandPath(), c.CommandPath())
+	}
+}
+
+func TestShortcutPrecedence(t *testing.T) {
+	rootCmd := &Command{Use: "tool", Run: emptyRun}
+	storageCmd := &Command{Use: "storage"}
+	storageCmd.AddCommand(&Command{Use: "list", Run: emptyRun})
+	rootCmd.AddCommand(storageCmd, &Command{Use: "lsof", Run: emptyRun})
+	rootCmd.AddShortcut("ls", "storage list")
+	rootCmd.AddShortcut("lsof", "storage list")
+	rootCmd.AddShortcut("broken", "storage missing")
+
+	if c, _, _ := rootCmd.Find([]string{"lsof"}); c.Name() != "lsof" {
+		t.Errorf("Expected the lsof command to win over the shortcut, got %q", c.CommandPath())
+	}
+	if c, _, _ := rootCmd.Find([]string{"broken"}); c != rootCmd {
+		t.Errorf("Expected a shortcut to a missing command to be ignored, got %q", c.CommandPath())
+	}
+
+	var names []string
+	for _, s := range rootCmd.Shortcuts() {
+		names = append(names, s.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"ls", "lsof"}) {
+		t.Errorf("Expected shortcuts [ls lsof], got %v", names)
+	}
+}
+
+func TestShortcutHelpAndCompletion(t *testing.T) {
+	rootCmd := &Command{Use: "tool", Run: emptyRun}
+	storageCmd := &Command{Use: "storage"}
+	objectsCmd := &Command{Use: "objects"}
+	listCmd := &Command{Use: "list", Run: emptyRun}
+	listCmd.Flags().BoolP("long", "l", false, "")
+	objectsCmd.AddCommand(listCmd)
+	storageCmd.AddCommand(objectsCmd)
+	rootCmd.AddCommand(storageCmd, &Command{Use: "lsof", Run: emptyRun})
+	rootCmd.AddShortcut("ls", "storage objects list")
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "Shortcuts:\n  ls          tool storage objects list\n")
+
+	if got := strings.Join(rootCmd.completions(nil, "ls"), " "); got != "ls lsof" {
+		t.Errorf("Expected completions %q, got %q", "ls lsof", got)
+	}
+	if got := strings.Join(rootCmd.completions([]string{"ls"}, "--"), " "); got != "--help --long" {
+		t.Errorf("Expected completions %q, got %q", "--help --long", got)
+	}
+
+	shortcuts := listCmd.ShortcutsTo()
+	if len(shortcuts) != 1 || shortcuts[0].Owner != rootCmd || shortcuts[0].CommandPath() != "tool ls" {
+		t.Errorf("Unexpected shortcuts to list: %+v", shortcuts)
+	}
+}
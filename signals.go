@@ -0,0 +1,83 @@
+package cobra
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// exit terminates the program; it can be replaced in tests.
+var exit = os.Exit
+
+// HandleSignals makes executions of the command tree of c handle SIGINT and
+// SIGTERM. On the first signal, the context returned by Context is cancelled,
+// so the command can stop its work and return; its post hooks and the
+// functions registered with OnFinalize still run. A second signal terminates
+// the program immediately, as does not returning within grace after the
+// first signal, unless grace is 0. The exit code is 128 plus the number of
+// the signal, as in shells: 130 for SIGINT and 143 for SIGTERM.
+//
+// Commands must watch their context, e.g. cmd.Context().Done(), to be
+// cancelled gracefully.
+func (c *Command) HandleSignals(grace time.Duration) {
+	root := c.Root()
+	root.signalHandling = true
+	root.signalGrace = grace
+}
+
+// handleSignals cancels the context of c on the first interrupt of an
+// execution and exits on the second one or after the grace period. It
+// returns a function that stops handling signals once the execution is done.
+func (c *Command) handleSignals() (stop func()) {
+	parent := c.ctx
+	ctx, cancel := context.WithCancel(c.Context())
+	c.ctx = ctx
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		var first os.Signal
+		select {
+		case first = <-signals:
+			c.tracef("received %v, cancelling the execution", first)
+			cancel()
+		case <-done:
+			return
+		}
+
+		var timeout <-chan time.Time
+		if c.signalGrace > 0 {
+			timer := time.NewTimer(c.signalGrace)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case sig := <-signals:
+			c.tracef("received %v again, exiting", sig)
+			exit(signalExitCode(sig))
+		case <-timeout:
+			c.tracef("execution did not finish within %v, exiting", c.signalGrace)
+			exit(signalExitCode(first))
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+		c.ctx = parent
+	}
+}
+
+// signalExitCode returns the exit code of a program terminated by sig.
+func signalExitCode(sig os.Signal) int {
+	if n, ok := sig.(syscall.Signal); ok {
+		return 128 + int(n)
+	}
+	return 128 + int(syscall.SIGINT)
+}
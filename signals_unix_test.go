@@ -0,0 +1,153 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package cobra
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// stubExit replaces exit for a test and returns the channel receiving the
+// exit codes.
+func stubExit() (codes chan int, restore func()) {
+	codes = make(chan int, 1)
+	exit = func(code int) { codes <- code }
+	return codes, func() { exit = os.Exit }
+}
+
+func interrupt(t *testing.T) {
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandleSignalsCancelsContext(t *testing.T) {
+	var postRun, finalized bool
+	OnFinalize(func() { finalized = true })
+	defer func() { finalizers = nil }()
+
+	rootCmd := &Command{
+		Use: "root",
+		RunE: func(cmd *Command, args []string) error {
+			interrupt(t)
+			select {
+			case <-cmd.Context().Done():
+				return cmd.Context().Err()
+			case <-time.After(5 * time.Second):
+				return nil
+			}
+		},
+		PersistentPostRun: func(*Command, []string) { postRun = true },
+	}
+	rootCmd.HandleSignals(0)
+
+	_, err := executeCommand(rootCmd)
+	if err != context.Canceled {
+		t.Errorf("Expected %v, got %v", context.Canceled, err)
+	}
+	if postRun {
+		t.Error("Expected the post hooks not to run after a failed RunE")
+	}
+	if !finalized {
+		t.Error("Expected the finalizers to run")
+	}
+	if rootCmd.Context() != context.Background() {
+		t.Error("Expected the context to be reset after the execution")
+	}
+}
+
+func TestHandleSignalsPostHooksAfterCancel(t *testing.T) {
+	var postRun bool
+	rootCmd := &Command{
+		Use: "root",
+		Run: func(cmd *Command, args []string) {
+			interrupt(t)
+			<-cmd.Context().Done()
+		},
+		PostRun: func(*Command, []string) { postRun = true },
+	}
+	rootCmd.HandleSignals(time.Minute)
+
+	if _, err := executeCommand(rootCmd); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	if !postRun {
+		t.Error("Expected the post hooks to run")
+	}
+}
+
+func TestHandleSignalsSecondSignalExits(t *testing.T) {
+	codes, restore := stubExit()
+	defer restore()
+
+	var code int
+	rootCmd := &Command{
+		Use: "root",
+		Run: func(cmd *Command, args []string) {
+			interrupt(t)
+			<-cmd.Context().Done()
+			interrupt(t)
+			select {
+			case code = <-codes:
+			case <-time.After(5 * time.Second):
+			}
+		},
+	}
+	rootCmd.HandleSignals(0)
+
+	executeCommand(rootCmd)
+	if code != 130 {
+		t.Errorf("Expected exit code 130, got %d", code)
+	}
+}
+
+func TestHandleSignalsGraceTimeout(t *testing.T) {
+	codes, restore := stubExit()
+	defer restore()
+
+	var code int
+	rootCmd := &Command{
+		Use: "root",
+		Run: func(cmd *Command, args []string) {
+			interrupt(t)
+			select {
+			case code = <-codes:
+			case <-time.After(5 * time.Second):
+			}
+		},
+	}
+	rootCmd.HandleSignals(10 * time.Millisecond)
+
+	executeCommand(rootCmd)
+	if code != 130 {
+		t.Errorf("Expected exit code 130, got %d", code)
+	}
+}
+
+func TestHandleSignalsTerminateExitCode(t *testing.T) {
+	codes, restore := stubExit()
+	defer restore()
+
+	var code int
+	rootCmd := &Command{
+		Use: "root",
+		Run: func(cmd *Command, args []string) {
+			if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case code = <-codes:
+			case <-time.After(5 * time.Second):
+			}
+		},
+	}
+	rootCmd.HandleSignals(10 * time.Millisecond)
+
+	executeCommand(rootCmd)
+	if code != 143 {
+		t.Errorf("Expected exit code 143, got %d", code)
+	}
+}
@@ -0,0 +1,165 @@
+package cobra
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Stability describes how mature a command or flag is.
+type Stability string
+
+const (
+	// StabilityStable is the stability of commands and flags that don't set one.
+	StabilityStable Stability = ""
+	// StabilityBeta marks commands and flags that are feature complete but may still change.
+	StabilityBeta Stability = "beta"
+	// StabilityAlpha marks commands and flags that are likely to change.
+	StabilityAlpha Stability = "alpha"
+	// StabilityExperimental marks commands and flags that may change or go away at any
+	// time. They can only be used once experimental features are enabled, either through
+	// ExperimentalEnv or the flag added by AddExperimentalFlag.
+	StabilityExperimental Stability = "experimental"
+)
+
+// FlagStabilityAnnotation is the flag annotation holding the stability set by MarkFlagStability.
+const FlagStabilityAnnotation = "cobra_annotation_stability"
+
+// ExperimentalEnv is the name of the environment variable that, when set to a
+// non-empty value, enables experimental commands and flags.
+var ExperimentalEnv = "COBRA_EXPERIMENTAL"
+
+// ExperimentalFlagName is the name of the flag added by AddExperimentalFlag.
+var ExperimentalFlagName = "experimental"
+
+// AddExperimentalFlag adds a persistent boolean flag named ExperimentalFlagName
+// to the root command of c, which enables experimental commands and flags.
+func (c *Command) AddExperimentalFlag() {
+	c.Root().PersistentFlags().Bool(ExperimentalFlagName, false, "enable experimental commands and flags")
+}
+
+// ExperimentalEnabled returns true if experimental commands and flags can be used,
+// either because ExperimentalEnv is set or because the flag added by
+// AddExperimentalFlag was passed.
+func (c *Command) ExperimentalEnabled() bool {
+	if len(os.Getenv(ExperimentalEnv)) > 0 {
+		return true
+	}
+	f := c.Root().PersistentFlags().Lookup(ExperimentalFlagName)
+	return f != nil && f.Value.Type() == "bool" && f.Value.String() == "true"
+}
+
+// IsExperimental returns true if c or one of its parents is experimental.
+func (c *Command) IsExperimental() bool {
+	for p := c; p != nil; p = p.parent {
+		if p.Stability == StabilityExperimental {
+			return true
+		}
+	}
+	return false
+}
+
+// experimentalAllowed returns false if c is experimental and experimental
+// commands are not enabled.
+func (c *Command) experimentalAllowed() bool {
+	return !c.IsExperimental() || c.ExperimentalEnabled()
+}
+
+// StabilityLabel returns the label shown next to the command in help listings,
+// e.g. "(alpha)", or an empty string for stable commands.
+func (c *Command) StabilityLabel() string {
+	return stabilityLabel(c.Stability)
+}
+
+func stabilityLabel(s Stability) string {
+	if s == StabilityStable {
+		return ""
+	}
+	return "(" + string(s) + ")"
+}
+
+// experimentalError explains how to enable experimental features.
+func (c *Command) experimentalError(what string) error {
+	enable := fmt.Sprintf("set %s=1", ExperimentalEnv)
+	if c.Root().PersistentFlags().Lookup(ExperimentalFlagName) != nil {
+		enable += fmt.Sprintf(" or pass --%s", ExperimentalFlagName)
+	}
+	return fmt.Errorf("%s is experimental and may change or be removed; %s to use it", what, enable)
+}
+
+// checkExperimental returns an error if c, or one of the flags that were
+// passed to it, is experimental while experimental features are disabled.
+func (c *Command) checkExperimental() error {
+	if c.ExperimentalEnabled() {
+		return nil
+	}
+	if c.IsExperimental() {
+		return c.experimentalError(fmt.Sprintf("command %q", c.CommandPath()))
+	}
+
+	var names []string
+	c.Flags().Visit(func(f *pflag.Flag) {
+		if flagStability(f) == StabilityExperimental {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	if len(names) > 0 {
+		return c.experimentalError(fmt.Sprintf("flag %s", strings.Join(names, ", ")))
+	}
+	return nil
+}
+
+// flagStability returns the stability of f set by MarkFlagStability.
+func flagStability(f *pflag.Flag) Stability {
+	if s, ok := f.Annotations[FlagStabilityAnnotation]; ok && len(s) > 0 {
+		return Stability(s[0])
+	}
+	return StabilityStable
+}
+
+// FlagAllowed returns false if f is experimental and experimental flags
+// are not enabled.
+func (c *Command) FlagAllowed(f *pflag.Flag) bool {
+	return flagStability(f) != StabilityExperimental || c.ExperimentalEnabled()
+}
+
+// MarkFlagStability sets the stability of a flag if it exists. Help and docs
+// label the flag with it, e.g. "(alpha)", see UsageFlags.
+func MarkFlagStability(flags *pflag.FlagSet, name string, s Stability) error {
+	if flags.Lookup(name) == nil {
+		return fmt.Errorf("flag %q does not exist", name)
+	}
+	return flags.SetAnnotation(name, FlagStabilityAnnotation, []string{string(s)})
+}
+
+// UsageFlags returns a copy of flags as help and docs show them: experimental
+// flags are left out while experimental features are not enabled, and the
+// usage of the other flags is prefixed with their stability label.
+func (c *Command) UsageFlags(flags *pflag.FlagSet) *pflag.FlagSet {
+	usageFlags := pflag.NewFlagSet(c.Name(), pflag.ContinueOnError)
+	usageFlags.SortFlags = flags.SortFlags
+	usageFlags.SetNormalizeFunc(flags.GetNormalizeFunc())
+	flags.VisitAll(func(f *pflag.Flag) {
+		if !c.FlagAllowed(f) {
+			return
+		}
+		usageFlag := *f
+		if label := stabilityLabel(flagStability(f)); len(label) > 0 {
+			usageFlag.Usage = label + " " + f.Usage
+		}
+		usageFlags.AddFlag(&usageFlag)
+	})
+	return usageFlags
+}
+
+// MarkFlagStability sets the stability of a local flag of c.
+func (c *Command) MarkFlagStability(name string, s Stability) error {
+	return MarkFlagStability(c.Flags(), name, s)
+}
+
+// MarkPersistentFlagStability sets the stability of a persistent flag of c.
+func (c *Command) MarkPersistentFlagStability(name string, s Stability) error {
+	return MarkFlagStability(c.PersistentFlags(), name, s)
+}
@@ -0,0 +1,135 @@
+package cobra
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestStabilityLabelsInHelp(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(
+		&Command{Use: "new", Short: "A new command", Stability: StabilityAlpha, Run: emptyRun},
+		&Command{Use: "newer", Short: "A newer command", Stability: StabilityBeta, Run: emptyRun},
+		&Command{Use: "labs", Stability: StabilityExperimental, Run: emptyRun},
+	)
+	rootCmd.Flags().Bool("fast", false, "go fast")
+	rootCmd.MarkFlagStability("fast", StabilityAlpha)
+	rootCmd.MarkFlagStability("fast", StabilityAlpha)
+	rootCmd.Flags().Bool("turbo", false, "go faster")
+	rootCmd.MarkFlagStability("turbo", StabilityExperimental)
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, output, "A new command (alpha)")
+	checkStringContains(t, output, "A newer command (beta)")
+	checkStringContains(t, output, "   (alpha) go fast\n")
+	checkStringOmits(t, output, "labs")
+	checkStringOmits(t, output, "turbo")
+	if usage := rootCmd.Flags().Lookup("fast").Usage; usage != "go fast" {
+		t.Errorf("Expected the usage of the flag to be kept, got %q", usage)
+	}
+}
+
+func TestExperimentalCommandDisabled(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	labsCmd := &Command{Use: "labs", Stability: StabilityExperimental, Run: emptyRun}
+	labsCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
+	rootCmd.AddCommand(labsCmd)
+
+	for _, args := range [][]string{{"labs"}, {"labs", "sub"}} {
+		rootCmd.ResetFlagsState()
+		_, err := executeCommand(rootCmd, args...)
+		expected := `command "root ` + strings.Join(args, " ") + `" is experimental and may change or be removed; set COBRA_EXPERIMENTAL=1 to use it`
+		if err == nil || err.Error() != expected {
+			t.Errorf("Expected error %q, got %v", expected, err)
+		}
+	}
+}
+
+func TestExperimentalFlagDisabled(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().Bool("turbo", false, "")
+	rootCmd.MarkFlagStability("turbo", StabilityExperimental)
+	rootCmd.AddExperimentalFlag()
+
+	_, err := executeCommand(rootCmd, "--turbo")
+	expected := "flag --turbo is experimental and may change or be removed; set COBRA_EXPERIMENTAL=1 or pass --experimental to use it"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected error %q, got %v", expected, err)
+	}
+}
+
+func TestExperimentalEnabledByFlag(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	labsCmd := &Command{Use: "labs", Stability: StabilityExperimental, Run: emptyRun}
+	labsCmd.AddCommand(&Command{Use: "sub", Run: emptyRun})
+	rootCmd.AddCommand(labsCmd)
+	rootCmd.Flags().Bool("turbo", false, "")
+	rootCmd.MarkFlagStability("turbo", StabilityExperimental)
+	rootCmd.AddExperimentalFlag()
+
+	if _, err := executeCommand(rootCmd, "labs", "sub", "--experimental"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	rootCmd.ResetFlagsState()
+	if _, err := executeCommand(rootCmd, "--experimental", "--turbo"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestExperimentalEnabledByEnv(t *testing.T) {
+	os.Setenv(ExperimentalEnv, "1")
+	defer os.Unsetenv(ExperimentalEnv)
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "labs", Short: "Labs features", Stability: StabilityExperimental, Run: emptyRun})
+	rootCmd.Flags().Bool("turbo", false, "go faster")
+	rootCmd.MarkFlagStability("turbo", StabilityExperimental)
+
+	if _, err := executeCommand(rootCmd, "labs"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+	rootCmd.ResetFlagsState()
+	if _, err := executeCommand(rootCmd, "--turbo"); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	output, _ := executeCommand(rootCmd, "--help")
+	checkStringContains(t, output, "Labs features (experimental)")
+	checkStringContains(t, output, "(experimental) go faster")
+
+	if got := strings.Join(rootCmd.completions(nil, "-"), " "); got != "--help --turbo -h" {
+		t.Errorf("Expected completions %q, got %q", "--help --turbo -h", got)
+	}
+}
+
+func TestExperimentalCompletions(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(
+		&Command{Use: "new", Stability: StabilityAlpha, Run: emptyRun},
+		&Command{Use: "newer", Stability: StabilityBeta, Run: emptyRun},
+		&Command{Use: "labs", Stability: StabilityExperimental, Run: emptyRun},
+	)
+	rootCmd.Flags().Bool("turbo", false, "")
+	rootCmd.MarkFlagStability("turbo", StabilityExperimental)
+
+	if got := strings.Join(rootCmd.completions(nil, ""), " "); got != "new newer" {
+		t.Errorf("Expected completions %q, got %q", "new newer", got)
+	}
+	if got := strings.Join(rootCmd.completions(nil, "-"), " "); got != "--help -h" {
+		t.Errorf("Expected completions %q, got %q", "--help -h", got)
+	}
+
+	buf := new(bytes.Buffer)
+	rootCmd.GenBashCompletion(buf)
+	checkOmit(t, buf.String(), "labs")
+	checkOmit(t, buf.String(), "turbo")
+
+	buf.Reset()
+	rootCmd.GenZshCompletion(buf)
+	checkOmit(t, buf.String(), "labs")
+}
@@ -0,0 +1,302 @@
+package cobra
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"sync"
+
+	flag "github.com/spf13/pflag"
+)
+
+// flagSnapshot holds the state of a flag value from before the flag was
+// parsed for the first time, so that ResetFlagsState can restore it.
+type flagSnapshot struct {
+	// value is a copy of what the flag's Value points to.
+	value reflect.Value
+	// slice is a copy of the list of a flag.SliceValue, which keeps the
+	// list behind a second pointer that a copy of the value would share.
+	slice []string
+}
+
+func newFlagSnapshot(f *flag.Flag) flagSnapshot {
+	var s flagSnapshot
+	if sv, ok := f.Value.(flag.SliceValue); ok {
+		s.slice = append([]string{}, sv.GetSlice()...)
+	}
+	if rv := reflect.ValueOf(f.Value); rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		s.value = reflect.New(rv.Elem().Type()).Elem()
+		s.value.Set(rv.Elem())
+	}
+	return s
+}
+
+// restore sets the value of f back to the snapshot and marks f as unchanged.
+func (s flagSnapshot) restore(f *flag.Flag) {
+	defer func() { f.Changed = false }()
+
+	if !s.value.IsValid() {
+		f.Value.Set(f.DefValue)
+		return
+	}
+
+	// Restoring the copy also resets the internal state of the value, like
+	// the flag pflag's slices and maps use to decide whether setting them
+	// replaces or extends their data.
+	elem := reflect.ValueOf(f.Value).Elem()
+	elem.Set(s.value)
+	if s.value.Kind() != reflect.Struct {
+		return
+	}
+
+	// Values with a struct type may keep their data behind a pointer, which
+	// the copy shares, so their data has to be restored separately.
+	if sv, ok := f.Value.(flag.SliceValue); ok {
+		sv.Replace(s.slice)
+		return
+	}
+	def := f.DefValue
+	if strings.HasPrefix(f.Value.Type(), "stringTo") {
+		// pflag's maps print their value in brackets, but don't parse them.
+		def = strings.TrimSuffix(strings.TrimPrefix(def, "["), "]")
+	}
+	if f.Value.Set(def) == nil {
+		elem.Set(s.value)
+	}
+}
+
+// snapshotFlags records the state of all flags of c that haven't been
+// recorded or changed yet.
+func (c *Command) snapshotFlags() {
+	if c.flagSnapshots == nil {
+		c.flagSnapshots = make(map[*flag.Flag]flagSnapshot)
+	}
+	c.Flags().VisitAll(func(f *flag.Flag) {
+		if _, ok := c.flagSnapshots[f]; ok || f.Changed {
+			return
+		}
+		c.flagSnapshots[f] = newFlagSnapshot(f)
+	})
+}
+
+// ResetFlagsState restores the state that executing c or its subcommands
+// leaves behind: flag values and their Changed state, the name the
+// commands were called as, the arguments set by SetArgs and the buffered
+// flag error messages. This allows executing the same command tree more
+// than once, e.g. in tests or in a long-running process.
+//
+// Flag values are restored to what they were before the flag was parsed
+// for the first time. Flags of a custom struct type, and pflag's map types,
+// are restored by setting their default value again, which leaves maps
+// with an empty default untouched.
+func (c *Command) ResetFlagsState() {
+	for f, s := range c.flagSnapshots {
+		if f.Changed {
+			s.restore(f)
+		}
+	}
+
+	if c.flags != nil {
+		// The parsed FlagSet remembers which flags were set and the
+		// arguments left after parsing; start over with a fresh one.
+		flags := flag.NewFlagSet(c.Name(), flag.ContinueOnError)
+		copyFlagSetSettings(flags, c.flags)
+		flags.SetOutput(c.flagErrorBuf)
+		c.flags.VisitAll(flags.AddFlag)
+		c.flags = flags
+	}
+	if c.flagErrorBuf != nil {
+		c.flagErrorBuf.Reset()
+	}
+	c.commandCalledAs.name = ""
+	c.commandCalledAs.called = false
+	c.args = nil
+
+	for _, sub := range c.commands {
+		sub.ResetFlagsState()
+	}
+}
+
+// copyFlagSetSettings configures dst like src.
+func copyFlagSetSettings(dst, src *flag.FlagSet) {
+	dst.Usage = src.Usage
+	dst.SortFlags = src.SortFlags
+	dst.ParseErrorsWhitelist = src.ParseErrorsWhitelist
+	dst.SetNormalizeFunc(src.GetNormalizeFunc())
+	// pflag has no getter for this setting, but it can be read through reflection.
+	dst.SetInterspersed(reflect.ValueOf(src).Elem().FieldByName("interspersed").Bool())
+}
+
+// Clone returns a deep copy of c and its subcommands, without a parent.
+// A clone can be executed independently of, and concurrently with, c and
+// other clones, so a long-running process can dispatch many command lines
+// by executing each one in its own clone.
+//
+// Flag values are copied, not shared. Flags that were bound to variables
+// with the *Var functions don't update those variables when the clone is
+// executed, so commands run in clones must read their flags through their
+// FlagSet, e.g. with cmd.Flags().GetString(name). Values of custom flag
+// types that keep their data behind a pointer remain shared.
+//
+// Cloning reads the lazily initialized state of c, so Clone may be called
+// from several goroutines at once, but not while c itself is executed.
+func (c *Command) Clone() *Command {
+	cloneMu.Lock()
+	defer cloneMu.Unlock()
+	return c.clone()
+}
+
+// cloneMu serializes cloning, which updates caches of the source tree, such
+// as merged and sorted flags.
+var cloneMu sync.Mutex
+
+func (c *Command) clone() *Command {
+	c.mergePersistentFlags()
+
+	clone := *c
+	clone.parent = nil
+	clone.commands = nil
+	clone.helpCommand = nil
+	clone.args = nil
+	clone.commandCalledAs.name = ""
+	clone.commandCalledAs.called = false
+	clone.flagErrorBuf = new(bytes.Buffer)
+	clone.flags = nil
+	clone.pflags = nil
+	clone.lflags = nil
+	clone.iflags = nil
+	clone.parentsPflags = nil
+	clone.flagSnapshots = nil
+	clone.deprecationWarnings = nil
+	if c.Annotations != nil {
+		clone.Annotations = make(map[string]string, len(c.Annotations))
+		for k, v := range c.Annotations {
+			clone.Annotations[k] = v
+		}
+	}
+	if c.flagValidators != nil {
+		clone.flagValidators = make(map[string][]FlagValidator, len(c.flagValidators))
+		for k, v := range c.flagValidators {
+			clone.flagValidators[k] = append([]FlagValidator(nil), v...)
+		}
+	}
+	if c.flagGroups != nil {
+		clone.flagGroups = make([][]string, len(c.flagGroups))
+		for i, group := range c.flagGroups {
+			clone.flagGroups[i] = append([]string(nil), group...)
+		}
+	}
+	if c.shortcuts != nil {
+		clone.shortcuts = make(map[string]string, len(c.shortcuts))
+		for k, v := range c.shortcuts {
+			clone.shortcuts[k] = v
+		}
+	}
+
+	pflags := c.PersistentFlags()
+	copyFlagSetSettings(clone.PersistentFlags(), pflags)
+	pflags.VisitAll(func(f *flag.Flag) {
+		clone.pflags.AddFlag(cloneFlag(pflags, f))
+	})
+
+	flags := c.Flags()
+	copyFlagSetSettings(clone.Flags(), flags)
+	flags.VisitAll(func(f *flag.Flag) {
+		// Skip persistent flags, which are added back when the flags of
+		// the clone are merged.
+		if pflags.Lookup(f.Name) == f || c.parentsPflags.Lookup(f.Name) == f {
+			return
+		}
+		clone.flags.AddFlag(cloneFlag(flags, f))
+	})
+
+	for _, sub := range c.commands {
+		subClone := sub.clone()
+		if sub == c.helpCommand {
+			clone.helpCommand = subClone
+		}
+		clone.AddCommand(subClone)
+	}
+	return &clone
+}
+
+// cloneFlag returns a copy of f, a flag of fs, with its own value.
+func cloneFlag(fs *flag.FlagSet, f *flag.Flag) *flag.Flag {
+	clone := *f
+	clone.Value = cloneFlagValue(fs, f)
+	if f.Annotations != nil {
+		clone.Annotations = make(map[string][]string, len(f.Annotations))
+		for k, v := range f.Annotations {
+			clone.Annotations[k] = v
+		}
+	}
+	return &clone
+}
+
+func cloneFlagValue(fs *flag.FlagSet, f *flag.Flag) flag.Value {
+	if v := cloneCompositeFlagValue(fs, f); v != nil {
+		return v
+	}
+	rv := reflect.ValueOf(f.Value)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return f.Value
+	}
+	clone := reflect.New(rv.Elem().Type())
+	clone.Elem().Set(rv.Elem())
+	return clone.Interface().(flag.Value)
+}
+
+// cloneCompositeFlagValue creates a new value for the pflag types that keep
+// their data behind a second pointer, which a copy of the value would share.
+// It returns nil for all other types.
+func cloneCompositeFlagValue(fs *flag.FlagSet, f *flag.Flag) flag.Value {
+	name := f.Name
+	dst := flag.NewFlagSet(name, flag.ContinueOnError)
+	switch f.Value.Type() {
+	case "stringSlice":
+		v, _ := fs.GetStringSlice(name)
+		dst.StringSlice(name, v, "")
+	case "stringArray":
+		v, _ := fs.GetStringArray(name)
+		dst.StringArray(name, v, "")
+	case "intSlice":
+		v, _ := fs.GetIntSlice(name)
+		dst.IntSlice(name, v, "")
+	case "int32Slice":
+		v, _ := fs.GetInt32Slice(name)
+		dst.Int32Slice(name, v, "")
+	case "int64Slice":
+		v, _ := fs.GetInt64Slice(name)
+		dst.Int64Slice(name, v, "")
+	case "uintSlice":
+		v, _ := fs.GetUintSlice(name)
+		dst.UintSlice(name, v, "")
+	case "float32Slice":
+		v, _ := fs.GetFloat32Slice(name)
+		dst.Float32Slice(name, v, "")
+	case "float64Slice":
+		v, _ := fs.GetFloat64Slice(name)
+		dst.Float64Slice(name, v, "")
+	case "boolSlice":
+		v, _ := fs.GetBoolSlice(name)
+		dst.BoolSlice(name, v, "")
+	case "durationSlice":
+		v, _ := fs.GetDurationSlice(name)
+		dst.DurationSlice(name, v, "")
+	case "ipSlice":
+		v, _ := fs.GetIPSlice(name)
+		dst.IPSlice(name, v, "")
+	case "stringToString":
+		v, _ := fs.GetStringToString(name)
+		dst.StringToString(name, v, "")
+	case "stringToInt":
+		v, _ := fs.GetStringToInt(name)
+		dst.StringToInt(name, v, "")
+	case "stringToInt64":
+		v, _ := fs.GetStringToInt64(name)
+		dst.StringToInt64(name, v, "")
+	default:
+		return nil
+	}
+	return dst.Lookup(name).Value
+}
@@ -0,0 +1,163 @@
+package cobra
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestResetFlagsState(t *testing.T) {
+	var name string
+	var tags []string
+	var labels map[string]string
+	var count int
+
+	var changed bool
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	childCmd := &Command{
+		Use:     "child",
+		Aliases: []string{"kid"},
+		Run: func(c *Command, _ []string) {
+			changed = c.Flags().Changed("name")
+		},
+	}
+	rootCmd.PersistentFlags().StringVar(&name, "name", "default", "")
+	childCmd.Flags().StringSliceVar(&tags, "tag", []string{"a", "b"}, "")
+	childCmd.Flags().StringToStringVar(&labels, "label", map[string]string{"k": "v"}, "")
+	childCmd.Flags().CountVarP(&count, "verbose", "v", "")
+	rootCmd.AddCommand(childCmd)
+
+	if _, err := executeCommand(rootCmd, "kid", "--name", "x", "--tag", "c", "--tag", "d", "--label", "x=y", "-vv"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name != "x" || strings.Join(tags, ",") != "c,d" || labels["x"] != "y" || count != 2 || !changed {
+		t.Fatalf("Unexpected flag values after first execution: %q %v %v %d %v", name, tags, labels, count, changed)
+	}
+
+	rootCmd.ResetFlagsState()
+
+	if name != "default" {
+		t.Errorf("Expected name to be reset to %q, got %q", "default", name)
+	}
+	if strings.Join(tags, ",") != "a,b" {
+		t.Errorf("Expected tags to be reset to [a b], got %v", tags)
+	}
+	if len(labels) != 1 || labels["k"] != "v" {
+		t.Errorf("Expected labels to be reset to map[k:v], got %v", labels)
+	}
+	if count != 0 {
+		t.Errorf("Expected count to be reset to 0, got %d", count)
+	}
+	if childCmd.Flags().Lookup("name").Changed || childCmd.Flags().Changed("tag") {
+		t.Error("Expected flags to be unchanged after reset")
+	}
+	if childCmd.CalledAs() != "" {
+		t.Errorf("Expected CalledAs to be reset, got %q", childCmd.CalledAs())
+	}
+
+	// A slice flag must replace, not append to, its default after a reset.
+	if _, err := executeCommand(rootCmd, "child", "--tag", "e"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Join(tags, ",") != "e" {
+		t.Errorf("Expected tags to be [e], got %v", tags)
+	}
+	if name != "default" || changed {
+		t.Errorf("Expected name to keep its default, got %q (changed: %v)", name, changed)
+	}
+	if childCmd.CalledAs() != "child" {
+		t.Errorf("Expected CalledAs to be %q, got %q", "child", childCmd.CalledAs())
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	var name string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringVar(&name, "name", "default", "")
+	childCmd := &Command{Use: "child", Annotations: map[string]string{"key": "value"}, Run: emptyRun}
+	childCmd.Flags().StringSlice("tag", []string{"a"}, "")
+	rootCmd.AddCommand(childCmd)
+
+	clone := rootCmd.Clone()
+	if clone.HasParent() {
+		t.Error("Expected the clone to have no parent")
+	}
+
+	c, _, err := executeCommandC(clone, "child", "--name", "x", "--tag", "b")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if c == childCmd {
+		t.Fatal("Expected a cloned command to be executed")
+	}
+	if got, _ := c.Flags().GetString("name"); got != "x" {
+		t.Errorf("Expected cloned name flag to be %q, got %q", "x", got)
+	}
+	if got, _ := c.Flags().GetStringSlice("tag"); strings.Join(got, ",") != "b" {
+		t.Errorf("Expected cloned tag flag to be [b], got %v", got)
+	}
+	c.Annotations["key"] = "changed"
+
+	if name != "default" {
+		t.Errorf("Expected original name variable to be untouched, got %q", name)
+	}
+	if got, _ := childCmd.Flags().GetStringSlice("tag"); strings.Join(got, ",") != "a" {
+		t.Errorf("Expected original tag flag to be untouched, got %v", got)
+	}
+	if childCmd.Flags().Changed("tag") || childCmd.CalledAs() != "" {
+		t.Error("Expected original command to be untouched")
+	}
+	if childCmd.Annotations["key"] != "value" {
+		t.Error("Expected original annotations to be untouched")
+	}
+}
+
+func TestCloneKeepsFlagSetSettings(t *testing.T) {
+	rootCmd := &Command{Use: "root", Args: ArbitraryArgs, Run: emptyRun}
+	rootCmd.Flags().Bool("b", false, "")
+	rootCmd.Flags().SetInterspersed(false)
+
+	clone := rootCmd.Clone()
+	if _, err := executeCommand(clone, "arg", "--b"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if clone.Flags().Changed("b") {
+		t.Error("Expected --b after a positional argument not to be parsed as a flag")
+	}
+}
+
+func TestConcurrentClones(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().String("name", "", "")
+	echoCmd := &Command{
+		Use: "echo",
+		Run: func(c *Command, args []string) {
+			name, _ := c.Flags().GetString("name")
+			tags, _ := c.Flags().GetStringSlice("tag")
+			c.Print(name, " ", strings.Join(tags, ","), " ", strings.Join(args, ","))
+		},
+	}
+	echoCmd.Flags().StringSlice("tag", nil, "")
+	rootCmd.AddCommand(echoCmd)
+	// Initialize everything that is lazily set up on the first execution.
+	rootCmd.InitDefaultHelpCmd()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clone := rootCmd.Clone()
+			n := fmt.Sprint(i)
+			output, err := executeCommand(clone, "echo", "--name", n, "--tag", n, n)
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if expected := n + " " + n + " " + n; output != expected {
+				t.Errorf("Expected %q, got %q", expected, output)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,204 @@
+package cobra
+
+import (
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+
+	flag "github.com/spf13/pflag"
+)
+
+// Theme styles help and usage messages, and errors printed by ExecuteC, with
+// ANSI escape sequences. Each field holds the SGR parameters used for one
+// kind of text, e.g. "1" for bold or "1;36" for bold cyan; empty fields leave
+// the text unstyled.
+type Theme struct {
+	// Heading styles section headings such as "Usage:".
+	Heading string
+	// Command styles command names.
+	Command string
+	// Flag styles flag names.
+	Flag string
+	// Placeholder styles placeholders such as "[flags]" and flag value types.
+	Placeholder string
+	// Error styles the "Error:" prefix of error messages.
+	Error string
+	// Suggestion styles the commands suggested for unknown commands.
+	Suggestion string
+}
+
+var (
+	// DefaultTheme is a theme using colors.
+	DefaultTheme = &Theme{
+		Heading:     "1",
+		Command:     "36",
+		Flag:        "33",
+		Placeholder: "2",
+		Error:       "1;31",
+		Suggestion:  "32",
+	}
+
+	// MonochromeTheme is a theme using bold and underlined text only.
+	MonochromeTheme = &Theme{
+		Heading:     "1",
+		Command:     "1",
+		Flag:        "1",
+		Placeholder: "4",
+		Error:       "1",
+		Suggestion:  "1",
+	}
+)
+
+// NoColorEnv is the name of the environment variable that, when set to a
+// non-empty value, disables themes, as described at https://no-color.org.
+var NoColorEnv = "NO_COLOR"
+
+// SetTheme sets the theme used for the default help and usage templates of c
+// and its subcommands, and for the errors printed by ExecuteC. The theme is
+// only applied when the output is a terminal and NO_COLOR is not set.
+func (c *Command) SetTheme(theme *Theme) {
+	c.theme = theme
+}
+
+// activeTheme returns the theme to use for output of c written to w, or nil
+// if the output should not be styled.
+func (c *Command) activeTheme(w io.Writer) *Theme {
+	for p := c; p != nil; p = p.parent {
+		if p.theme != nil {
+			if len(os.Getenv(NoColorEnv)) > 0 || !isTerminal(w) {
+				return nil
+			}
+			return p.theme
+		}
+	}
+	return nil
+}
+
+// style wraps the text in s, but not its surrounding whitespace, in the
+// escape sequences for the SGR parameters sgr.
+func style(sgr, s string) string {
+	if len(sgr) == 0 {
+		return s
+	}
+	text := strings.TrimSpace(s)
+	if len(text) == 0 {
+		return s
+	}
+	i := strings.Index(s, text)
+	return s[:i] + "\x1b[" + sgr + "m" + text + "\x1b[0m" + s[i+len(text):]
+}
+
+var (
+	flagUsageRegexp   = regexp.MustCompile(`(?m)^(\s*)(-\S, )?(--[^\s=]+)( \S+)?`)
+	placeholderRegexp = regexp.MustCompile(`\[[^\]]*\]|<[^>]*>`)
+)
+
+// styledUsageTemplate returns the usage template of c to render with theme.
+// The default template is replaced by one that styles its text, so that
+// UsageTemplate keeps returning a template that renders with the template
+// functions of cobra alone.
+func (c *Command) styledUsageTemplate(theme *Theme) string {
+	t := c.UsageTemplate()
+	if theme == nil || t != defaultUsageTemplate {
+		return t
+	}
+	return themedUsageTemplate
+}
+
+// themedUsageTemplate is the default usage template with its headings,
+// command names, flags and placeholders styled.
+const themedUsageTemplate = `{{heading "Usage:"}}{{if .Runnable}}
+  {{useLine .UseLine}}{{end}}{{if .HasAvailableSubCommands}}
+  {{.CommandPath}} {{placeholder "[command]"}}{{end}}{{if gt (len .Aliases) 0}}
+
+{{heading "Aliases:"}}
+  {{.NameAndAliases}}{{end}}{{if .HasExample}}
+
+{{heading "Examples:"}}
+{{.Example}}{{end}}{{if .HasAvailableSubCommands}}
+
+{{heading "Available Commands:"}}{{range .Commands}}{{if (or .IsAvailableCommand (eq .Name "help"))}}
+  {{command (rpad .Name .NamePadding)}} {{.Short}}{{with .StabilityLabel}} {{.}}{{end}}{{end}}{{end}}{{end}}{{if .HasAvailableShortcuts}}
+
+{{heading "Shortcuts:"}}
+{{.ShortcutsUsage | trimTrailingWhitespaces}}{{end}}{{if .HasUserAliases}}
+
+{{heading "User Aliases:"}}
+{{.UserAliasesUsage | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableLocalFlags}}
+
+{{heading "Flags:"}}
+{{flagUsages (.UsageFlags .LocalFlags) | trimTrailingWhitespaces}}{{end}}{{if .HasAvailableInheritedFlags}}
+
+{{heading "Global Flags:"}}
+{{flagUsages (.UsageFlags .InheritedFlags) | trimTrailingWhitespaces}}{{end}}{{if .HasHelpSubCommands}}
+
+{{heading "Additional help topics:"}}{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
+  {{command (rpad .CommandPath .CommandPathPadding)}} {{.Short}}{{end}}{{end}}{{end}}{{if .HasAvailableSubCommands}}
+
+Use "{{.CommandPath}} [command] --help" for more information about a command.{{end}}
+`
+
+// themeFuncs returns the template functions that style text with theme,
+// which may be nil.
+func themeFuncs(theme *Theme) template.FuncMap {
+	if theme == nil {
+		theme = &Theme{}
+	}
+	return template.FuncMap{
+		"heading":     func(s string) string { return style(theme.Heading, s) },
+		"command":     func(s string) string { return style(theme.Command, s) },
+		"flag":        func(s string) string { return style(theme.Flag, s) },
+		"placeholder": func(s string) string { return style(theme.Placeholder, s) },
+		"useLine": func(s string) string {
+			return placeholderRegexp.ReplaceAllStringFunc(s, func(p string) string {
+				return style(theme.Placeholder, p)
+			})
+		},
+		"flagUsages": func(fs *flag.FlagSet) string {
+			return styleFlagUsages(fs.FlagUsages(), theme)
+		},
+	}
+}
+
+// styleFlagUsages styles the flag names and value placeholders in usages,
+// as returned by FlagUsages.
+func styleFlagUsages(usages string, theme *Theme) string {
+	if len(theme.Flag) == 0 && len(theme.Placeholder) == 0 {
+		return usages
+	}
+	return flagUsageRegexp.ReplaceAllStringFunc(usages, func(line string) string {
+		m := flagUsageRegexp.FindStringSubmatch(line)
+		styled := m[1]
+		if len(m[2]) > 0 {
+			styled += style(theme.Flag, m[2][:2]) + ", "
+		}
+		styled += style(theme.Flag, m[3])
+		if len(m[4]) > 0 {
+			styled += " " + style(theme.Placeholder, m[4][1:])
+		}
+		return styled
+	})
+}
+
+// styleError styles the "Error:" prefix and the suggestions in the error
+// message msg printed by ExecuteC.
+func styleError(msg string, theme *Theme) string {
+	if theme == nil {
+		return "Error: " + msg
+	}
+	lines := strings.Split(msg, "\n")
+	inSuggestions := false
+	for i, line := range lines {
+		switch {
+		case line == "Did you mean this?":
+			inSuggestions = true
+		case inSuggestions && strings.HasPrefix(line, "\t"):
+			lines[i] = "\t" + style(theme.Suggestion, line[1:])
+		default:
+			inSuggestions = false
+		}
+	}
+	return style(theme.Error, "Error:") + " " + strings.Join(lines, "\n")
+}
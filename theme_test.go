@@ -0,0 +1,125 @@
+package cobra
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func setUpThemeTest() (tearDown func()) {
+	oldIsTerminal := isTerminal
+	isTerminal = func(io.Writer) bool { return true }
+	return func() { isTerminal = oldIsTerminal }
+}
+
+func TestThemeHelp(t *testing.T) {
+	defer setUpThemeTest()()
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().StringP("name", "n", "", "the name")
+	rootCmd.AddCommand(&Command{Use: "greet [name]", Short: "Greet someone", Run: emptyRun})
+	rootCmd.SetTheme(&Theme{Heading: "1", Command: "36", Flag: "33", Placeholder: "2"})
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "\x1b[1mUsage:\x1b[0m\n  root \x1b[2m[flags]\x1b[0m\n")
+	checkStringContains(t, output, "\n  \x1b[36mgreet\x1b[0m       Greet someone\n")
+	checkStringContains(t, output, "\x1b[33m-n\x1b[0m, \x1b[33m--name\x1b[0m \x1b[2mstring\x1b[0m   the name\n")
+	checkStringContains(t, output, "\x1b[33m-h\x1b[0m, \x1b[33m--help\x1b[0m          help for root\n")
+}
+
+func TestThemeError(t *testing.T) {
+	defer setUpThemeTest()()
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "greet", Run: emptyRun})
+	rootCmd.SetTheme(&Theme{Error: "31", Suggestion: "32"})
+
+	output, err := executeCommand(rootCmd, "gret")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	checkStringContains(t, output, "\x1b[31mError:\x1b[0m unknown command \"gret\" for \"root\"\n\nDid you mean this?\n\t\x1b[32mgreet\x1b[0m\n")
+}
+
+func TestThemeDisabled(t *testing.T) {
+	tests := []struct {
+		name  string
+		setUp func() func()
+	}{
+		{"no terminal", func() func() { return func() {} }},
+		{"NO_COLOR", func() func() {
+			tearDown := setUpThemeTest()
+			os.Setenv(NoColorEnv, "1")
+			return func() {
+				os.Unsetenv(NoColorEnv)
+				tearDown()
+			}
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer tc.setUp()()
+			rootCmd := &Command{Use: "root", Run: emptyRun}
+			rootCmd.Flags().StringP("name", "n", "", "the name")
+			rootCmd.AddCommand(&Command{Use: "greet", Run: emptyRun})
+			rootCmd.SetTheme(&Theme{Heading: "1", Flag: "33", Error: "31"})
+
+			output, _ := executeCommand(rootCmd, "--help")
+			if strings.Contains(output, "\x1b[") {
+				t.Errorf("Expected no escape sequences, got %q", output)
+			}
+			checkStringContains(t, output, "  -n, --name string   the name\n")
+
+			rootCmd.ResetFlagsState()
+			output, _ = executeCommand(rootCmd, "gret")
+			checkStringContains(t, output, "Error: unknown command")
+		})
+	}
+}
+
+func TestThemeNotSet(t *testing.T) {
+	defer setUpThemeTest()()
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.SetTheme(&Theme{Heading: "1"})
+	rootCmd.SetTheme(nil)
+	output, _ := executeCommand(rootCmd, "--help")
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Expected no escape sequences, got %q", output)
+	}
+}
+
+func TestThemeUsageTemplateWithoutThemeFuncs(t *testing.T) {
+	defer setUpThemeTest()()
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.Flags().String("name", "", "the name")
+	rootCmd.SetTheme(DefaultTheme)
+
+	// Custom renderers only know the template functions of cobra.
+	tmpl, err := template.New("usage").Funcs(templateFuncs).Parse(rootCmd.UsageTemplate())
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, rootCmd); err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, buf.String(), "Flags:\n      --name string   the name\n")
+}
+
+func TestStyle(t *testing.T) {
+	if got := style("1", "  name   "); got != "  \x1b[1mname\x1b[0m   " {
+		t.Errorf("Unexpected styled text %q", got)
+	}
+	if got := style("", "name"); got != "name" {
+		t.Errorf("Unexpected unstyled text %q", got)
+	}
+}
@@ -0,0 +1,179 @@
+package cobra
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// TraceEnv is the name of the environment variable that, when set to a
+// non-empty value, enables tracing of executions.
+var TraceEnv = "COBRA_TRACE"
+
+// ExplainFlagName is the name of the flag added by AddExplainFlag.
+var ExplainFlagName = "explain"
+
+// SetTrace enables or disables tracing of the executions of the command tree
+// of c, which can also be enabled through TraceEnv. A trace is written to the
+// error output and shows how the command line was resolved: the command that
+// was found, the flags that were set on the command line and the commands
+// they belong to, the positional arguments, how long each hook and Run
+// function took and where an error occurred. The values of flags that were
+// not set are left out, as they may come from the environment or from
+// configuration files and hold secrets.
+func (c *Command) SetTrace(enabled bool) {
+	c.Root().traceEnabled = enabled
+}
+
+// TraceEnabled returns true if executions of c are traced.
+func (c *Command) TraceEnabled() bool {
+	return len(os.Getenv(TraceEnv)) > 0 || c.Root().traceEnabled
+}
+
+// tracef writes a line to the trace of c if tracing is enabled.
+func (c *Command) tracef(format string, a ...interface{}) {
+	if c.TraceEnabled() {
+		fmt.Fprintf(c.ErrOrStderr(), "[cobra] "+format+"\n", a...)
+	}
+}
+
+// AddExplainFlag adds a persistent boolean flag named ExplainFlagName to the
+// root command of c. When it is passed, the command line is resolved and its
+// flags and arguments are validated, but instead of running the hooks and Run
+// functions of the command, an explanation of the resolution and a list of
+// the functions that would have run is written to the output.
+func (c *Command) AddExplainFlag() {
+	c.Root().PersistentFlags().Bool(ExplainFlagName, false, "show how the command line is interpreted without running it")
+}
+
+// explaining returns true if the flag added by AddExplainFlag was passed to c.
+func (c *Command) explaining() bool {
+	f := c.Flags().Lookup(ExplainFlagName)
+	return f != nil && f == c.Root().PersistentFlags().Lookup(ExplainFlagName) &&
+		f.Value.Type() == "bool" && f.Value.String() == "true"
+}
+
+// traceResolution traces the flags and positional arguments of c after its
+// flags were parsed.
+func (c *Command) traceResolution(args []string) {
+	if !c.TraceEnabled() {
+		return
+	}
+	for _, line := range c.flagResolution() {
+		c.tracef("flag %s", line)
+	}
+	c.tracef("positional args: %q", args)
+}
+
+// flagResolution describes the value of each flag of c that was set on the
+// command line and the command it was inherited from.
+func (c *Command) flagResolution() []string {
+	var lines []string
+	c.Flags().Visit(func(f *flag.Flag) {
+		line := fmt.Sprintf("--%s=%q", f.Name, f.Value.String())
+		if owner := c.persistentFlagOwner(f); owner != nil && owner != c {
+			line += fmt.Sprintf(" (inherited from %q)", owner.CommandPath())
+		}
+		lines = append(lines, line)
+	})
+	return lines
+}
+
+// persistentFlagOwner returns the command among c and its parents that
+// defines f as a persistent flag, or nil.
+func (c *Command) persistentFlagOwner(f *flag.Flag) *Command {
+	for p := c; p != nil; p = p.Parent() {
+		if p.PersistentFlags().Lookup(f.Name) == f {
+			return p
+		}
+	}
+	return nil
+}
+
+// runHook runs fn, the function name of owner, e.g. "PreRunE", and traces
+// how long it took and whether it failed.
+func (c *Command) runHook(name string, owner *Command, fn func() error) error {
+	if !c.TraceEnabled() {
+		return fn()
+	}
+	start := time.Now()
+	err := fn()
+	if err != nil {
+		c.tracef("%s of %q failed after %v: %v", name, owner.CommandPath(), time.Since(start), err)
+	} else {
+		c.tracef("%s of %q ran in %v", name, owner.CommandPath(), time.Since(start))
+	}
+	return err
+}
+
+// plannedHooks returns the hooks and Run functions an execution of c would
+// run, in order, in the form "PreRunE of "root sub"".
+func (c *Command) plannedHooks() []string {
+	var hooks []string
+	add := func(name string, owner *Command) {
+		hooks = append(hooks, fmt.Sprintf("%s of %q", name, owner.CommandPath()))
+	}
+	for p := c; p != nil; p = p.Parent() {
+		if p.PersistentPreRunE != nil {
+			add("PersistentPreRunE", p)
+			break
+		} else if p.PersistentPreRun != nil {
+			add("PersistentPreRun", p)
+			break
+		}
+	}
+	if c.PreRunE != nil {
+		add("PreRunE", c)
+	} else if c.PreRun != nil {
+		add("PreRun", c)
+	}
+	if c.RunE != nil {
+		add("RunE", c)
+	} else {
+		add("Run", c)
+	}
+	if c.PostRunE != nil {
+		add("PostRunE", c)
+	} else if c.PostRun != nil {
+		add("PostRun", c)
+	}
+	for p := c; p != nil; p = p.Parent() {
+		if p.PersistentPostRunE != nil {
+			add("PersistentPostRunE", p)
+			break
+		} else if p.PersistentPostRun != nil {
+			add("PersistentPostRun", p)
+			break
+		}
+	}
+	return hooks
+}
+
+// explain validates args, the positional arguments of c, and writes the
+// explanation of the execution to w.
+func (c *Command) explain(w io.Writer, args []string) error {
+	if err := c.ValidateArgs(args); err != nil {
+		return err
+	}
+	if err := c.validateRequiredFlags(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Command: %s\n", c.CommandPath())
+	if c.CalledAs() != c.Name() {
+		fmt.Fprintf(w, "Called as: %s\n", c.CalledAs())
+	}
+	fmt.Fprintln(w, "Flags:")
+	for _, line := range c.flagResolution() {
+		fmt.Fprintf(w, "  %s\n", line)
+	}
+	fmt.Fprintf(w, "Args: %q\n", args)
+	fmt.Fprintln(w, "Would run:")
+	for _, hook := range c.plannedHooks() {
+		fmt.Fprintf(w, "  %s\n", hook)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package cobra
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	rootCmd := &Command{Use: "root", PersistentPreRun: func(*Command, []string) {}, Run: emptyRun}
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "")
+	rootCmd.PersistentFlags().String("token", "secret", "")
+	childCmd := &Command{Use: "child", Aliases: []string{"kid"}, RunE: func(*Command, []string) error { return nil }}
+	childCmd.Flags().String("out", "text", "")
+	rootCmd.AddCommand(childCmd)
+	rootCmd.SetTrace(true)
+
+	_, stderr, _, err := executeCommandSplit(rootCmd, "kid", "--out", "json", "-v", "arg")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	checkStringContains(t, stderr, `[cobra] args: ["kid" "--out" "json" "-v" "arg"]`)
+	checkStringContains(t, stderr, `[cobra] resolved command "root child", called as "kid", with args ["--out" "json" "-v" "arg"]`)
+	checkStringContains(t, stderr, `[cobra] flag --out="json"`+"\n")
+	checkStringContains(t, stderr, `[cobra] flag --verbose="true" (inherited from "root")`)
+	checkStringOmits(t, stderr, "secret")
+	checkStringContains(t, stderr, `[cobra] positional args: ["arg"]`)
+	checkStringContains(t, stderr, `[cobra] PersistentPreRun of "root" ran in `)
+	checkStringContains(t, stderr, `[cobra] RunE of "root child" ran in `)
+}
+
+func TestTraceEnv(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun, SilenceErrors: true}
+	rootCmd.AddCommand(&Command{Use: "child", RunE: func(*Command, []string) error { return errors.New("boom") }})
+
+	_, stderr, _, _ := executeCommandSplit(rootCmd, "child")
+	checkStringOmits(t, stderr, "[cobra]")
+
+	os.Setenv(TraceEnv, "1")
+	defer os.Unsetenv(TraceEnv)
+	rootCmd.ResetFlagsState()
+	_, stderr, _, err := executeCommandSplit(rootCmd, "child")
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	checkStringContains(t, stderr, `[cobra] RunE of "root child" failed after `)
+	checkStringContains(t, stderr, `[cobra] error in "root child": boom`)
+
+	rootCmd.ResetFlagsState()
+	_, stderr, _, _ = executeCommandSplit(rootCmd, "child", "--unknown")
+	checkStringContains(t, stderr, `[cobra] error in "root child": unknown flag: --unknown`)
+}
+
+func TestExplain(t *testing.T) {
+	var ran bool
+	rootCmd := &Command{Use: "root", PersistentPreRun: func(*Command, []string) {}, Run: emptyRun}
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "")
+	childCmd := &Command{
+		Use:     "child",
+		Aliases: []string{"kid"},
+		Args:    MaximumNArgs(1),
+		RunE:    func(*Command, []string) error { ran = true; return nil },
+	}
+	childCmd.Flags().String("out", "text", "")
+	rootCmd.AddCommand(childCmd)
+	rootCmd.AddExplainFlag()
+
+	output, err := executeCommand(rootCmd, "kid", "--explain", "-v", "arg")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ran {
+		t.Error("Expected the command not to run")
+	}
+	expected := `Command: root child
+Called as: kid
+Flags:
+  --explain="true" (inherited from "root")
+  --verbose="true" (inherited from "root")
+Args: ["arg"]
+Would run:
+  PersistentPreRun of "root"
+  RunE of "root child"
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "child", "--explain", "a", "b")
+	if err == nil {
+		t.Error("Expected the explanation to validate the arguments")
+	}
+}
@@ -0,0 +1,165 @@
+package cobra
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SetUserAliasesFunc sets the function that provides the aliases defined by
+// the users of the program, such as "co" for "checkout --force". It maps
+// alias names to the command line they expand to, which is split into words
+// like a POSIX shell does. The function is called every time an alias may be
+// needed, so the aliases can change while the program runs.
+//
+// User aliases only apply to the first command name after the root command
+// and never shadow its subcommands or their aliases.
+func (c *Command) SetUserAliasesFunc(f func() (map[string]string, error)) {
+	c.Root().userAliasesFunc = f
+}
+
+// SetUserAliasesFile makes the root command load the aliases defined by the
+// users of the program from filename. Each line of the file has the form
+// `name = expansion`, where the expansion may be double quoted; blank lines
+// and lines starting with '#' are ignored. A missing file defines no aliases.
+func (c *Command) SetUserAliasesFile(filename string) {
+	c.SetUserAliasesFunc(func() (map[string]string, error) {
+		return readUserAliasesFile(filename)
+	})
+}
+
+// UserAliases returns the aliases defined by the users of the program that
+// don't shadow a subcommand of the root command.
+func (c *Command) UserAliases() (map[string]string, error) {
+	root := c.Root()
+	if root.userAliasesFunc == nil {
+		return nil, nil
+	}
+	aliases, err := root.userAliasesFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	available := make(map[string]string, len(aliases))
+	for name, expansion := range aliases {
+		if root.findNext(name) == nil {
+			available[name] = expansion
+		}
+	}
+	return available, nil
+}
+
+// HasUserAliases determines if c is the root command and has user aliases.
+func (c *Command) HasUserAliases() bool {
+	if c.HasParent() {
+		return false
+	}
+	aliases, err := c.UserAliases()
+	return err == nil && len(aliases) > 0
+}
+
+// UserAliasesUsage returns the user aliases of c formatted for the usage
+// message, one per line.
+func (c *Command) UserAliasesUsage() string {
+	aliases, _ := c.UserAliases()
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	buf := new(bytes.Buffer)
+	for _, name := range names {
+		fmt.Fprintf(buf, "  %s %s\n", rpad(name, c.NamePadding()), aliases[name])
+	}
+	return buf.String()
+}
+
+// expandUserAliases replaces the first command name in args with the
+// expansion of the user alias of that name, until it names a subcommand
+// or something that is not an alias.
+func (c *Command) expandUserAliases(args []string) ([]string, error) {
+	if c.userAliasesFunc == nil {
+		return args, nil
+	}
+
+	var aliases map[string]string
+	seen := make(map[string]bool)
+	for {
+		// The first word stripFlags keeps is the command name.
+		positions := nonFlagArgs(args, c)
+		if len(positions) == 0 {
+			return args, nil
+		}
+		pos := positions[0]
+		name := args[pos]
+		if c.findNext(name) != nil {
+			return args, nil
+		}
+
+		if aliases == nil {
+			var err error
+			if aliases, err = c.UserAliases(); err != nil {
+				return args, err
+			}
+		}
+		expansion, ok := aliases[name]
+		if !ok {
+			return args, nil
+		}
+		if seen[name] {
+			return args, fmt.Errorf("alias %q expands recursively", name)
+		}
+		seen[name] = true
+
+		words, err := splitShellWords(expansion)
+		if err != nil {
+			return args, fmt.Errorf("invalid alias %q: %v", name, err)
+		}
+		expanded := append([]string{}, args[:pos]...)
+		expanded = append(expanded, words...)
+		args = append(expanded, args[pos+1:]...)
+	}
+}
+
+// readUserAliasesFile reads the aliases defined in filename.
+func readUserAliasesFile(filename string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%s:%d: expected name = expansion", filename, n)
+		}
+		name := strings.TrimSpace(line[:i])
+		expansion := strings.TrimSpace(line[i+1:])
+		if len(name) == 0 || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("%s:%d: invalid alias name %q", filename, n, name)
+		}
+		if strings.HasPrefix(expansion, `"`) {
+			if expansion, err = strconv.Unquote(expansion); err != nil {
+				return nil, fmt.Errorf("%s:%d: invalid expansion of alias %q", filename, n, name)
+			}
+		}
+		aliases[name] = expansion
+	}
+	return aliases, scanner.Err()
+}
@@ -0,0 +1,204 @@
+package cobra
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUserAliasExpansion(t *testing.T) {
+	var gotArgs []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	checkoutCmd := &Command{
+		Use:     "checkout",
+		Aliases: []string{"co"},
+		Run:     func(_ *Command, args []string) { gotArgs = args },
+	}
+	checkoutCmd.Flags().Bool("force", false, "")
+	storageCmd := &Command{Use: "storage"}
+	listCmd := &Command{Use: "list", Run: emptyRun}
+	listCmd.Flags().Bool("all", false, "")
+	listCmd.Flags().StringP("output", "o", "", "")
+	storageCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(checkoutCmd, storageCmd)
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return map[string]string{
+			"lsa": "storage list --all -o wide",
+			"ll":  "lsa 'my bucket'",
+			"fco": "checkout --force",
+			"co":  "storage list",
+		}, nil
+	})
+
+	c, args, err := rootCmd.Find([]string{"ll", "extra"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "list" {
+		t.Errorf("Expected to find list, got %q", c.Name())
+	}
+	if expected := []string{"--all", "-o", "wide", "my bucket", "extra"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected args %v, got %v", expected, args)
+	}
+
+	if _, err := executeCommand(rootCmd, "fco", "main"); err != nil {
+		t.Fatal(err)
+	}
+	if force, _ := checkoutCmd.Flags().GetBool("force"); !force || !reflect.DeepEqual(gotArgs, []string{"main"}) {
+		t.Errorf("Expected fco to run checkout --force main, got args %v", gotArgs)
+	}
+
+	// Built-in commands and aliases win.
+	if c, _, _ := rootCmd.Find([]string{"co"}); c.Name() != "checkout" {
+		t.Errorf("Expected co to find checkout, got %q", c.Name())
+	}
+}
+
+func TestUserAliasAfterFlagValue(t *testing.T) {
+	var gotArgs []string
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().String("name", "", "")
+	checkoutCmd := &Command{
+		Use: "checkout",
+		Run: func(_ *Command, args []string) { gotArgs = args },
+	}
+	checkoutCmd.Flags().Bool("force", false, "")
+	rootCmd.AddCommand(checkoutCmd)
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return map[string]string{"fco": "checkout --force"}, nil
+	})
+
+	if _, err := executeCommand(rootCmd, "--name", "fco", "fco", "x"); err != nil {
+		t.Fatal(err)
+	}
+	if name, _ := checkoutCmd.Flags().GetString("name"); name != "fco" {
+		t.Errorf("Expected the value of --name to be kept, got %q", name)
+	}
+	if force, _ := checkoutCmd.Flags().GetBool("force"); !force || !reflect.DeepEqual(gotArgs, []string{"x"}) {
+		t.Errorf("Expected checkout --force x, got force=%v args=%v", force, gotArgs)
+	}
+}
+
+func TestUserAliasAfterShortFlagValue(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.PersistentFlags().StringP("name", "n", "", "")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "")
+	rootCmd.AddCommand(&Command{Use: "checkout", Run: emptyRun})
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return map[string]string{"fco": "checkout --force"}, nil
+	})
+
+	args, err := rootCmd.expandUserAliases([]string{"-n", "fco", "-v", "fco", "fco"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"-n", "fco", "-v", "checkout", "--force", "fco"}; !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected args %v, got %v", expected, args)
+	}
+	if got := stripFlags(args, rootCmd); len(got) == 0 || got[0] != "checkout" {
+		t.Errorf("Expected stripFlags to agree on the command name, got %v", got)
+	}
+}
+
+func TestUserAliasRecursion(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "checkout", Run: emptyRun})
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return map[string]string{"a": "b --x", "b": "a"}, nil
+	})
+
+	_, _, err := rootCmd.Find([]string{"a"})
+	if err == nil || err.Error() != `alias "a" expands recursively` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestUserAliasesFuncError(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "checkout", Run: emptyRun})
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return nil, errors.New("broken config")
+	})
+
+	if _, _, err := rootCmd.Find([]string{"checkout"}); err != nil {
+		t.Errorf("Expected built-in commands to work, got %v", err)
+	}
+	if _, _, err := rootCmd.Find([]string{"lsa"}); err == nil || err.Error() != "broken config" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestUserAliasesFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cobra-aliases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "aliases")
+	content := `# my aliases
+co = "checkout --force"
+
+lsa = storage list --all -o wide
+`
+	if err := ioutil.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddCommand(&Command{Use: "checkout", Aliases: []string{"co"}, Run: emptyRun})
+	rootCmd.SetUserAliasesFile(filename)
+
+	aliases, err := rootCmd.UserAliases()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := map[string]string{"lsa": "storage list --all -o wide"}; !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("Expected aliases %v, got %v", expected, aliases)
+	}
+
+	rootCmd.SetUserAliasesFile(filepath.Join(dir, "missing"))
+	if aliases, err := rootCmd.UserAliases(); err != nil || len(aliases) > 0 {
+		t.Errorf("Expected no aliases for a missing file, got %v, %v", aliases, err)
+	}
+
+	if err := ioutil.WriteFile(filename, []byte("co checkout\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.SetUserAliasesFile(filename)
+	if _, err := rootCmd.UserAliases(); err == nil || !strings.HasSuffix(err.Error(), "aliases:1: expected name = expansion") {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestUserAliasesHelpAndCompletion(t *testing.T) {
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	storageCmd := &Command{Use: "storage"}
+	listCmd := &Command{Use: "list", Run: emptyRun}
+	listCmd.Flags().Bool("all", false, "")
+	listCmd.Flags().StringP("output", "o", "", "")
+	storageCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(&Command{Use: "checkout", Aliases: []string{"co"}, Run: emptyRun}, storageCmd)
+	rootCmd.SetUserAliasesFunc(func() (map[string]string, error) {
+		return map[string]string{"lsa": "storage list --all", "co": "storage list"}, nil
+	})
+
+	output, err := executeCommand(rootCmd, "--help")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "User Aliases:\n  lsa         storage list --all\n")
+	checkStringOmits(t, output, "co          storage list")
+
+	if got := strings.Join(rootCmd.completions(nil, ""), " "); got != "checkout co help lsa storage" {
+		t.Errorf("Expected completions %q, got %q", "checkout co help lsa storage", got)
+	}
+	// The expansion of lsa already sets --all.
+	if got := strings.Join(rootCmd.completions([]string{"lsa"}, "-"), " "); got != "--help --output -h -o" {
+		t.Errorf("Expected completions %q, got %q", "--help --output -h -o", got)
+	}
+}
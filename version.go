@@ -0,0 +1,120 @@
+package cobra
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Build metadata of the program, meant to be set with the -X flag of the
+// linker, for instance:
+//   go build -ldflags "-X github.com/spf13/cobra.BuildCommit=$(git rev-parse HEAD)"
+var (
+	// BuildVersion is the semantic version of the program, used when the root
+	// command has no Version.
+	BuildVersion string
+	// BuildCommit is the git commit the program was built from.
+	BuildCommit string
+	// BuildDate is the date the program was built at.
+	BuildDate string
+)
+
+// VersionInfo describes the version of a program.
+type VersionInfo struct {
+	Version      string          `json:"version" yaml:"version"`
+	GitCommit    string          `json:"gitCommit,omitempty" yaml:"gitCommit,omitempty"`
+	BuildDate    string          `json:"buildDate,omitempty" yaml:"buildDate,omitempty"`
+	GoVersion    string          `json:"goVersion" yaml:"goVersion"`
+	Platform     string          `json:"platform" yaml:"platform"`
+	Dependencies []ModuleVersion `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// ModuleVersion is the version of a module the program depends on.
+type ModuleVersion struct {
+	Path    string `json:"path" yaml:"path"`
+	Version string `json:"version" yaml:"version"`
+	// Replace is the path of the module replacing this one, if any.
+	Replace string `json:"replace,omitempty" yaml:"replace,omitempty"`
+}
+
+// VersionInfo returns the version of the program c belongs to. The version is
+// the Version of the root command, BuildVersion or the version of the main
+// module; the git commit and build date come from BuildCommit and BuildDate.
+// When the program is built with Go 1.12 or later, with module support, the
+// versions of its dependencies are included too.
+func (c *Command) VersionInfo() VersionInfo {
+	info := VersionInfo{
+		Version:   c.Root().Version,
+		GitCommit: BuildCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+	if len(info.Version) == 0 {
+		info.Version = BuildVersion
+	}
+	readBuildInfo(&info)
+	return info
+}
+
+// defaultVersionCmdTemplate is the template of the version command when no
+// version template is set.
+const defaultVersionCmdTemplate = `{{with .Name}}{{printf "%s " .}}{{end}}{{with .VersionInfo}}{{printf "version %s" .Version}}{{if .GitCommit}}
+commit: {{.GitCommit}}{{end}}{{if .BuildDate}}
+built: {{.BuildDate}}{{end}}
+go: {{.GoVersion}} {{.Platform}}{{end}}
+`
+
+// AddVersionCommand adds a "version" subcommand to the root command of c,
+// which prints the VersionInfo of the program. It renders the template set
+// with SetVersionTemplate on the root command, where the fields of
+// VersionInfo are available as .VersionInfo, or, with the --output flag, the
+// version info as json or yaml, or only the version with "short".
+func (c *Command) AddVersionCommand() *Command {
+	root := c.Root()
+	versionCmd := &Command{
+		Use:   "version",
+		Short: "Print the version information",
+		Args:  NoArgs,
+		RunE: func(c *Command, args []string) error {
+			output, _ := c.Flags().GetString("output")
+			return writeVersion(c, c.Root(), output)
+		},
+	}
+	versionCmd.Flags().StringP("output", "o", "", "output format, one of json, yaml or short")
+	root.AddCommand(versionCmd)
+	return versionCmd
+}
+
+// writeVersion prints the version info of root in the given output format.
+func writeVersion(c, root *Command, output string) error {
+	info := root.VersionInfo()
+	out := c.OutOrStdout()
+	switch output {
+	case "":
+		text := defaultVersionCmdTemplate
+		if len(root.versionTemplate) > 0 {
+			text = root.versionTemplate
+		}
+		return tmpl(c.OutOrStdout(), text, root)
+	case "short":
+		fmt.Fprintln(out, info.Version)
+	case "json":
+		b, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(b))
+	case "yaml":
+		b, err := yaml.Marshal(info)
+		if err != nil {
+			return err
+		}
+		out.Write(b)
+	default:
+		return fmt.Errorf("invalid output format %q, must be one of json, yaml or short", output)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+// +build go1.12
+
+package cobra
+
+import "runtime/debug"
+
+// readBuildInfo completes info with the module information embedded in the
+// program.
+func readBuildInfo(info *VersionInfo) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	if len(info.Version) == 0 && bi.Main.Version != "(devel)" {
+		info.Version = bi.Main.Version
+	}
+	for _, dep := range bi.Deps {
+		m := ModuleVersion{Path: dep.Path, Version: dep.Version}
+		if dep.Replace != nil {
+			m.Replace = dep.Replace.Path
+			m.Version = dep.Replace.Version
+		}
+		info.Dependencies = append(info.Dependencies, m)
+	}
+}
@@ -0,0 +1,7 @@
+// +build !go1.12
+
+package cobra
+
+// readBuildInfo does nothing, as module information is only embedded in
+// programs built with Go 1.12 or later.
+func readBuildInfo(info *VersionInfo) {}
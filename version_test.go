@@ -0,0 +1,100 @@
+package cobra
+
+import (
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func setBuildMetadata(version, commit, date string) (reset func()) {
+	oldVersion, oldCommit, oldDate := BuildVersion, BuildCommit, BuildDate
+	BuildVersion, BuildCommit, BuildDate = version, commit, date
+	return func() {
+		BuildVersion, BuildCommit, BuildDate = oldVersion, oldCommit, oldDate
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	defer setBuildMetadata("", "abc123", "2019-06-01")()
+
+	rootCmd := &Command{Use: "root", Version: "1.2.3", Run: emptyRun}
+	rootCmd.AddVersionCommand()
+
+	output, err := executeCommand(rootCmd, "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "root version 1.2.3\ncommit: abc123\nbuilt: 2019-06-01\ngo: " + runtime.Version() + " " + runtime.GOOS + "/" + runtime.GOARCH + "\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestVersionCommandOutput(t *testing.T) {
+	defer setBuildMetadata("0.1.0", "abc123", "")()
+
+	rootCmd := &Command{Use: "root", Run: emptyRun}
+	rootCmd.AddVersionCommand()
+
+	output, _, _, err := executeCommandSplit(rootCmd, "version", "--output", "short")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "0.1.0\n" {
+		t.Errorf("Expected %q, got %q", "0.1.0\n", output)
+	}
+
+	rootCmd.ResetFlagsState()
+	output, _, _, err = executeCommandSplit(rootCmd, "version", "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info VersionInfo
+	if err := json.Unmarshal([]byte(output), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Version != "0.1.0" || info.GitCommit != "abc123" || info.GoVersion != runtime.Version() {
+		t.Errorf("Unexpected version info %+v", info)
+	}
+	checkStringOmits(t, output, "buildDate")
+
+	rootCmd.ResetFlagsState()
+	output, _, _, err = executeCommandSplit(rootCmd, "version", "-o", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStringContains(t, output, "version: 0.1.0\ngitCommit: abc123\n")
+
+	rootCmd.ResetFlagsState()
+	_, err = executeCommand(rootCmd, "version", "-o", "xml")
+	if err == nil || err.Error() != `invalid output format "xml", must be one of json, yaml or short` {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestVersionCommandTemplate(t *testing.T) {
+	defer setBuildMetadata("", "abc123", "")()
+
+	rootCmd := &Command{Use: "root", Version: "1.2.3", Run: emptyRun}
+	rootCmd.SetVersionTemplate(`{{.Version}} ({{.VersionInfo.GitCommit}})` + "\n")
+	rootCmd.AddVersionCommand()
+
+	output, err := executeCommand(rootCmd, "version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != "1.2.3 (abc123)\n" {
+		t.Errorf("Expected %q, got %q", "1.2.3 (abc123)\n", output)
+	}
+
+	// The version flag uses the same template.
+	rootCmd.ResetFlagsState()
+	output, err = executeCommand(rootCmd, "--version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(output, "1.2.3 (abc123)") {
+		t.Errorf("Expected %q, got %q", "1.2.3 (abc123)\n", output)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"io"
 	"os"
 	"strings"
+
+	"github.com/spf13/pflag"
 )
 
 // GenZshCompletionFile generates zsh completion file.
@@ -21,12 +23,22 @@ func (c *Command) GenZshCompletionFile(filename string) error {
 
 // GenZshCompletion generates a zsh completion file and writes to the passed writer.
 func (c *Command) GenZshCompletion(w io.Writer) error {
+	return c.genZshCompletion(w, true)
+}
+
+// GenZshCompletionNoDesc generates a zsh completion file without the
+// descriptions of arguments and flags and writes it to the passed writer.
+func (c *Command) GenZshCompletionNoDesc(w io.Writer) error {
+	return c.genZshCompletion(w, false)
+}
+
+func (c *Command) genZshCompletion(w io.Writer, descriptions bool) error {
 	buf := new(bytes.Buffer)
 
 	writeHeader(buf, c)
 	maxDepth := maxDepth(c)
-	writeLevelMapping(buf, maxDepth)
-	writeLevelCases(buf, maxDepth, c)
+	writeLevelMapping(buf, maxDepth, flagSpecs(c, descriptions))
+	writeLevelCases(buf, maxDepth, c, descriptions)
 
 	_, err := buf.WriteTo(w)
 	return err
@@ -38,6 +50,10 @@ func writeHeader(w io.Writer, cmd *Command) {
 
 func maxDepth(c *Command) int {
 	if len(c.Commands()) == 0 {
+		if len(c.ValidArgs) > 0 {
+			// The arguments are completed one level deeper.
+			return 1
+		}
 		return 0
 	}
 	maxDepthSub := 0
@@ -50,8 +66,11 @@ func maxDepth(c *Command) int {
 	return 1 + maxDepthSub
 }
 
-func writeLevelMapping(w io.Writer, numLevels int) {
+func writeLevelMapping(w io.Writer, numLevels int, flagSpecs []string) {
 	fmt.Fprintln(w, `_arguments \`)
+	for _, spec := range flagSpecs {
+		fmt.Fprintf(w, "  %s \\\n", zshQuote(spec))
+	}
 	for i := 1; i <= numLevels; i++ {
 		fmt.Fprintf(w, `  '%d: :->level%d' \`, i, i)
 		fmt.Fprintln(w)
@@ -60,13 +79,13 @@ func writeLevelMapping(w io.Writer, numLevels int) {
 	fmt.Fprintln(w)
 }
 
-func writeLevelCases(w io.Writer, maxDepth int, root *Command) {
+func writeLevelCases(w io.Writer, maxDepth int, root *Command, descriptions bool) {
 	fmt.Fprintln(w, "case $state in")
 	defer fmt.Fprintln(w, "esac")
 
 	for i := 1; i <= maxDepth; i++ {
 		fmt.Fprintf(w, "  level%d)\n", i)
-		writeLevel(w, root, i)
+		writeLevel(w, root, i, descriptions)
 		fmt.Fprintln(w, "  ;;")
 	}
 	fmt.Fprintln(w, "  *)")
@@ -74,7 +93,7 @@ func writeLevelCases(w io.Writer, maxDepth int, root *Command) {
 	fmt.Fprintln(w, "  ;;")
 }
 
-func writeLevel(w io.Writer, root *Command, i int) {
+func writeLevel(w io.Writer, root *Command, i int, descriptions bool) {
 	fmt.Fprintf(w, "    case $words[%d] in\n", i)
 	defer fmt.Fprintln(w, "    esac")
 
@@ -87,12 +106,159 @@ func writeLevel(w io.Writer, root *Command, i int) {
 		fmt.Fprintf(w, "        _arguments '%d: :(%s)'\n", i, strings.Join(names, " "))
 		fmt.Fprintln(w, "      ;;")
 	}
+	for _, c := range filterByLevel(root, i-1) {
+		if len(c.Commands()) == 0 && len(c.ValidArgs) > 0 {
+			writeValidArgsCase(w, c, descriptions)
+		}
+	}
 	fmt.Fprintln(w, "      *)")
 	fmt.Fprintln(w, "        _arguments '*: :_files'")
 	fmt.Fprintln(w, "      ;;")
 
 }
 
+// writeValidArgsCase completes the ValidArgs of c with _describe, which
+// shows their descriptions if descriptions is true.
+func writeValidArgsCase(w io.Writer, c *Command, descriptions bool) {
+	var args []string
+	for _, arg := range c.ValidArgs {
+		value := validArgValue(arg)
+		if c.IsDeprecatedValidArg(value) {
+			continue
+		}
+		item := strings.Replace(value, ":", `\:`, -1)
+		if desc := validArgDescription(arg); len(desc) > 0 && descriptions {
+			item += ":" + desc
+		}
+		args = append(args, zshQuote(item))
+	}
+	fmt.Fprintf(w, "      %s)\n", c.Name())
+	fmt.Fprintln(w, "        local -a args")
+	fmt.Fprintf(w, "        args=(%s)\n", strings.Join(args, " "))
+	fmt.Fprintln(w, "        _describe 'argument' args")
+	fmt.Fprintln(w, "      ;;")
+}
+
+// flagSpecs returns the _arguments specs for the flags of the available
+// commands in the tree of c, with the usage of the flags if descriptions is
+// true. Flags are matched by name, so of several flags with the same name
+// only the first is used.
+func flagSpecs(c *Command, descriptions bool) []string {
+	var specs []string
+	seen := make(map[string]bool)
+	var visit func(*Command)
+	visit = func(c *Command) {
+		c.NonInheritedFlags().VisitAll(func(f *pflag.Flag) {
+			if seen[f.Name] || f.Hidden || len(f.Deprecated) > 0 || !c.FlagAllowed(f) {
+				return
+			}
+			seen[f.Name] = true
+			specs = append(specs, zshFlagSpecs(c, f, descriptions)...)
+		})
+		for _, sub := range c.Commands() {
+			if len(sub.Deprecated) == 0 && !sub.Hidden && sub.experimentalAllowed() {
+				visit(sub)
+			}
+		}
+	}
+	visit(c)
+	return specs
+}
+
+// zshFlagSpecs returns the _arguments specs for the long and short forms of
+// the flag f of c. Unless f is repeatable, its forms exclude each other, so
+// _arguments doesn't offer f again once it is on the command line. They also
+// exclude the flags f is mutually exclusive with. Required flags are marked
+// in their description, as _arguments sorts the flags it offers.
+func zshFlagSpecs(c *Command, f *pflag.Flag, descriptions bool) []string {
+	long, short := "--"+f.Name, ""
+	if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+		short = "-" + f.Shorthand
+	}
+
+	var exclusions []string
+	repeatable := FlagRepeatable(f)
+	if !repeatable {
+		exclusions = zshFlagForms(f)
+	}
+	for _, name := range c.flagConflicts(f.Name) {
+		if conflict := c.Flags().Lookup(name); conflict != nil {
+			exclusions = append(exclusions, zshFlagForms(conflict)...)
+		}
+	}
+	var prefix string
+	if len(exclusions) > 0 {
+		prefix = "(" + strings.Join(exclusions, " ") + ")"
+	}
+	if repeatable {
+		prefix += "*"
+	}
+
+	var action string
+	if descriptions {
+		usage := f.Usage
+		if FlagRequired(f) {
+			usage = strings.TrimSpace(usage + " (required)")
+		}
+		action = fmt.Sprintf("[%s]", zshOptionDescription(usage))
+	}
+	if len(f.NoOptDefVal) > 0 {
+		specs := []string{prefix + long + action}
+		if len(short) > 0 {
+			specs = append(specs, prefix+short+action)
+		}
+		return specs
+	}
+
+	action += ":" + f.Name + ":" + zshFlagValueAction(f)
+	specs := []string{prefix + long + "=" + action}
+	if len(short) > 0 {
+		specs = append(specs, prefix+short+"+"+action)
+	}
+	return specs
+}
+
+// zshFlagForms returns the long and short forms of f.
+func zshFlagForms(f *pflag.Flag) []string {
+	forms := []string{"--" + f.Name}
+	if len(f.Shorthand) > 0 && len(f.ShorthandDeprecated) == 0 {
+		forms = append([]string{"-" + f.Shorthand}, forms...)
+	}
+	return forms
+}
+
+// zshFlagValueAction returns the _arguments action that completes the value
+// of f, or an empty action if the value can't be completed.
+func zshFlagValueAction(f *pflag.Flag) string {
+	if values := flagEnumValues(f); len(values) > 0 {
+		return "(" + strings.Join(values, " ") + ")"
+	}
+	if exts, ok := f.Annotations[BashCompFilenameExt]; ok {
+		if len(exts) == 0 {
+			return "_files"
+		}
+		return fmt.Sprintf(`_files -g "*.(%s)"`, strings.Join(exts, "|"))
+	}
+	if dirs, ok := f.Annotations[BashCompSubdirsInDir]; ok {
+		if len(dirs) == 1 {
+			return fmt.Sprintf("_files -/ -W %q", dirs[0])
+		}
+		return "_files -/"
+	}
+	return ""
+}
+
+// zshOptionDescription escapes the characters of s that end an option
+// description in an _arguments spec.
+func zshOptionDescription(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`).Replace(s)
+}
+
+// zshQuote quotes s with single quotes.
+func zshQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
 func filterByLevel(c *Command, l int) []*Command {
 	cs := make([]*Command, 0)
 	if l == 0 {
@@ -100,6 +266,9 @@ func filterByLevel(c *Command, l int) []*Command {
 		return cs
 	}
 	for _, s := range c.Commands() {
+		if len(s.Deprecated) > 0 || s.Hidden || !s.experimentalAllowed() {
+			continue
+		}
 		cs = append(cs, filterByLevel(s, l-1)...)
 	}
 	return cs
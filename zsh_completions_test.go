@@ -71,6 +71,24 @@ func TestZshCompletion(t *testing.T) {
 			}(),
 			expectedExpressions: []string{"(sub11 sub12)", "(sub21 sub22)"},
 		},
+		{
+			name: "valid args",
+			root: func() *Command {
+				r := &Command{Use: "kubectl"}
+				r.AddCommand(&Command{
+					Use:                 "get",
+					ValidArgs:           []string{"pod\tA group of containers", "node", "ns:default\tIt's the default namespace", "minion"},
+					DeprecatedValidArgs: map[string]string{"minion": "use node"},
+				})
+				return r
+			}(),
+			expectedExpressions: []string{
+				"  '2: :->level2' \\\n",
+				"      get)\n        local -a args\n",
+				`args=('pod:A group of containers' 'node' 'ns\:default:It'\''s the default namespace')`,
+				"_describe 'argument' args",
+			},
+		},
 	}
 
 	for _, tc := range tcs {
@@ -87,3 +105,31 @@ func TestZshCompletion(t *testing.T) {
 		})
 	}
 }
+
+func TestZshCompletionUsedAndRequiredFlags(t *testing.T) {
+	rootCmd := newFlagGroupsTestRoot()
+	getCmd, _, _ := rootCmd.Find([]string{"get"})
+	getCmd.Flags().String("file", "", "input file")
+	getCmd.MarkFlagFilename("file", "yaml", "json")
+
+	buf := new(bytes.Buffer)
+	rootCmd.GenZshCompletion(buf)
+	output := buf.String()
+
+	for _, expected := range []string{
+		`'(-o --output)--output=[output file (required)]:output:' \`,
+		`'(-o --output)-o+[output file (required)]:output:' \`,
+		`'*--tag=[tags to get]:tag:' \`,
+		`'*-t+[tags to get]:tag:' \`,
+		`'(-v --verbose)-v[verbose output]' \`,
+		`'(--json --yaml)--json[print JSON]' \`,
+		`'(--yaml --json)--yaml[print YAML]' \`,
+		`'(--file)--file=[input file]:file:_files -g "*.(yaml|json)"' \`,
+	} {
+		checkStringContains(t, output, expected)
+	}
+
+	buf.Reset()
+	rootCmd.GenZshCompletionNoDesc(buf)
+	checkStringContains(t, buf.String(), `'(--json --yaml)--json' \`)
+}